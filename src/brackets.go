@@ -0,0 +1,243 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "strings"
+)
+
+var bracketPairs = map[byte]byte{
+    '(': ')',
+    '[': ']',
+    '{': '}',
+}
+
+type bracketPos struct {
+    row int
+    col int
+    ch  byte
+}
+
+// bracketCache keeps the brackets of the last scanned buffer until the
+// buffer changes, so matching the cursor bracket on every frame does not
+// rescan the file.
+type bracketCache struct {
+    buffer   *Buffer
+    version  int
+    lang     string
+    brackets []bracketPos
+}
+
+// scanBrackets returns every bracket in the buffer that is real code,
+// skipping those inside strings and comments.
+func scanBrackets(b *Buffer, lang *Language) []bracketPos {
+    var result []bracketPos
+    var blockOpen, blockClose string
+    if len(lang.BlockComment) == 2 {
+        blockOpen, blockClose = lang.BlockComment[0], lang.BlockComment[1]
+    }
+
+    inBlock := false
+    var quote byte
+
+    for row := 0; row < b.LineCount(); row++ {
+        line := b.GetLine(row)
+        if quote != '`' {
+            quote = 0
+        }
+
+        for col := 0; col < len(line); col++ {
+            c := line[col]
+            switch {
+            case inBlock:
+                if strings.HasPrefix(line[col:], blockClose) {
+                    inBlock = false
+                    col += len(blockClose) - 1
+                }
+            case quote != 0:
+                if c == '\\' && quote != '`' {
+                    col++
+                } else if c == quote {
+                    quote = 0
+                }
+            case lang.LineComment != "" && strings.HasPrefix(line[col:], lang.LineComment):
+                col = len(line)
+            case blockOpen != "" && strings.HasPrefix(line[col:], blockOpen):
+                inBlock = true
+                col += len(blockOpen) - 1
+            case strings.IndexByte(lang.Quotes, c) >= 0:
+                quote = c
+            case c == '(' || c == ')' || c == '[' || c == ']' || c == '{' || c == '}':
+                result = append(result, bracketPos{row: row, col: col, ch: c})
+            }
+        }
+    }
+    return result
+}
+
+// brackets returns the code brackets of the tab's buffer, scanning it
+// again only after it has changed.
+func (e *Editor) brackets(tab *Tab) []bracketPos {
+    c := &e.bracketCache
+    lang := e.languageFor(tab)
+    if c.buffer != tab.buffer || c.version != tab.buffer.version || c.lang != lang.Name {
+        *c = bracketCache{tab.buffer, tab.buffer.version, lang.Name, scanBrackets(tab.buffer, lang)}
+    }
+    return c.brackets
+}
+
+// matchBracket finds the bracket matching the one at the cursor, or the
+// one just before it.
+func (e *Editor) matchBracket(tab *Tab, row, col int) (from, to Cursor, ok bool) {
+    line := tab.buffer.GetLine(row)
+    isBracket := func(i int) bool {
+        return i >= 0 && i < len(line) && strings.IndexByte("()[]{}", line[i]) >= 0
+    }
+    if !isBracket(col) && !isBracket(col-1) {
+        return from, to, false
+    }
+
+    brackets := e.brackets(tab)
+
+    target := -1
+    for i, br := range brackets {
+        if br.row == row && br.col == col {
+            target = i
+            break
+        }
+        if br.row == row && br.col == col-1 {
+            target = i
+        }
+    }
+    if target < 0 {
+        return from, to, false
+    }
+
+    open := brackets[target]
+    from = Cursor{Row: open.row, Col: open.col}
+    if closer, isOpen := bracketPairs[open.ch]; isOpen {
+        depth := 0
+        for _, br := range brackets[target+1:] {
+            if br.ch == open.ch {
+                depth++
+            } else if br.ch == closer {
+                if depth == 0 {
+                    return from, Cursor{Row: br.row, Col: br.col}, true
+                }
+                depth--
+            }
+        }
+        return from, to, false
+    }
+
+    var opener byte
+    for o, c := range bracketPairs {
+        if c == open.ch {
+            opener = o
+        }
+    }
+    depth := 0
+    for i := target - 1; i >= 0; i-- {
+        br := brackets[i]
+        if br.ch == open.ch {
+            depth++
+        } else if br.ch == opener {
+            if depth == 0 {
+                return from, Cursor{Row: br.row, Col: br.col}, true
+            }
+            depth--
+        }
+    }
+    return from, to, false
+}
+
+func (e *Editor) jumpToMatchingBracket(tab *Tab) {
+    _, to, ok := e.matchBracket(tab, tab.cursor.Row, tab.cursor.Col)
+    if !ok {
+        e.setStatusMsg("No matching bracket")
+        return
+    }
+    tab.clearSelection()
+    tab.cursor.Row = to.Row
+    tab.cursor.Col = to.Col
+    e.ensureCursorValid(tab)
+    e.setStatusMsg(fmt.Sprintf("Matching bracket at line %d, column %d", to.Row+1, to.Col+1))
+}
+
+// autoPair handles typing brackets and quotes when auto-closing is on:
+// openers get their closer inserted, and typing a closer that was
+// auto-inserted just steps over it. It reports whether the rune was
+// consumed.
+func (e *Editor) autoPair(tab *Tab, r rune) bool {
+    if !e.config.autoClose() || r > 127 {
+        return false
+    }
+
+    lang := e.languageFor(tab)
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    col := tab.cursor.Col
+    c := byte(r)
+
+    var next, prev byte
+    if col < len(line) {
+        next = line[col]
+    }
+    if col > 0 && col <= len(line) {
+        prev = line[col-1]
+    }
+
+    isQuote := strings.IndexByte(lang.Quotes, c) >= 0
+    isCloser := c == ')' || c == ']' || c == '}'
+
+    if (isCloser || isQuote) && next == c && tab.autoClosed > 0 {
+        tab.cursor.Col++
+        tab.autoClosed--
+        return true
+    }
+
+    closer, isOpener := bracketPairs[c]
+    if isQuote {
+        if prev == '\\' || isWordRune(rune(prev)) {
+            return false
+        }
+        closer = c
+    } else if !isOpener {
+        return false
+    }
+
+    if next != 0 && next != ' ' && next != '\t' && next != ')' && next != ']' && next != '}' && next != ',' && next != ';' {
+        return false
+    }
+
+    tab.buffer.InsertText(tab.cursor.Row, col, string([]byte{c, closer}))
+    tab.cursor.Col++
+    tab.autoClosed++
+    return true
+}
+
+// deletePair removes both halves of an empty bracket or quote pair when
+// Backspace is pressed between them.
+func (e *Editor) deletePair(tab *Tab) bool {
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    col := tab.cursor.Col
+    if col == 0 || col >= len(line) {
+        return false
+    }
+
+    open, close := line[col-1], line[col]
+    closer, isOpener := bracketPairs[open]
+    isQuote := open == close && strings.IndexByte(e.languageFor(tab).Quotes, open) >= 0
+    if !(isOpener && closer == close) && !isQuote {
+        return false
+    }
+
+    tab.buffer.SetLine(tab.cursor.Row, line[:col-1]+line[col+1:])
+    tab.cursor.Col--
+    if tab.autoClosed > 0 {
+        tab.autoClosed--
+    }
+    return true
+}
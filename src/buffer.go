@@ -5,20 +5,32 @@
 package main
 
 import (
-    "bufio"
     "fmt"
     "os"
     "strings"
+    "unicode/utf8"
 )
 
 const maxUndoLevels = 50
 
 type Buffer struct {
-    lines     []string
-    filename  string
-    modified  bool
-    undoStack []BufferState
-    redoStack []BufferState
+    lines      []string
+    filename   string
+    modified   bool
+    version    int
+    undoStack  []BufferState
+    redoStack  []BufferState
+    batchDepth int
+    batchDirty bool
+    lineEdits  []lineEdit
+
+    editorConfig *EditorConfig
+}
+
+// lineEdit records that lines [start, end) were replaced by count lines,
+// so the tab showing the buffer can move its folds along.
+type lineEdit struct {
+    start, end, count int
 }
 
 type BufferState struct {
@@ -27,13 +39,16 @@ type BufferState struct {
     cursorCol int
 }
 
-func NewBuffer(filename string) (*Buffer, error) {
+// NewBuffer creates a buffer and loads filename if it exists. ec holds
+// the file's EditorConfig settings and may be nil.
+func NewBuffer(filename string, ec *EditorConfig) (*Buffer, error) {
     b := &Buffer{
-        lines:     []string{""},
-        filename:  filename,
-        modified:  false,
-        undoStack: make([]BufferState, 0, maxUndoLevels),
-        redoStack: make([]BufferState, 0, maxUndoLevels),
+        lines:        []string{""},
+        filename:     filename,
+        modified:     false,
+        undoStack:    make([]BufferState, 0, maxUndoLevels),
+        redoStack:    make([]BufferState, 0, maxUndoLevels),
+        editorConfig: ec,
     }
 
     if filename != "" {
@@ -47,64 +62,68 @@ func NewBuffer(filename string) (*Buffer, error) {
     return b, nil
 }
 
+func (b *Buffer) charset() string {
+    if b.editorConfig == nil {
+        return ""
+    }
+    return b.editorConfig.Charset
+}
+
 func (b *Buffer) Load() error {
-    file, err := os.Open(b.filename)
+    data, err := os.ReadFile(b.filename)
     if err != nil {
         return err
     }
-    defer file.Close()
-
-    b.lines = []string{}
-    scanner := bufio.NewScanner(file)
-    
-    const maxCapacity = 1024 * 1024
-    buf := make([]byte, maxCapacity)
-    scanner.Buffer(buf, maxCapacity)
-
-    for scanner.Scan() {
-        b.lines = append(b.lines, scanner.Text())
-    }
 
-    if err := scanner.Err(); err != nil {
+    text, err := decodeText(data, b.charset())
+    if err != nil {
         return err
     }
 
-    if len(b.lines) == 0 {
-        b.lines = []string{""}
+    text = strings.TrimSuffix(text, "\n")
+    lines := strings.Split(text, "\n")
+    for i, line := range lines {
+        lines[i] = strings.TrimSuffix(line, "\r")
     }
+    b.restore(lines)
 
     b.modified = false
+    b.version++
     return nil
 }
 
+// Save writes the buffer, applying the EditorConfig line ending,
+// charset and final newline settings. Trailing whitespace is trimmed by
+// the editor before saving, as an undoable edit.
 func (b *Buffer) Save() error {
     if b.filename == "" {
         return fmt.Errorf("no filename specified")
     }
 
-    tempFile := b.filename + ".tmp"
-    file, err := os.Create(tempFile)
+    eol := "\n"
+    if ec := b.editorConfig; ec != nil {
+        eol = ec.lineEnding()
+    }
+
+    text := strings.Join(b.lines, eol)
+    if ec := b.editorConfig; ec != nil && ec.InsertFinalNewline != nil && *ec.InsertFinalNewline {
+        if !strings.HasSuffix(text, eol) {
+            text += eol
+        }
+    }
+
+    data, err := encodeText(text, b.charset())
     if err != nil {
         return err
     }
 
-    writer := bufio.NewWriter(file)
-    for i, line := range b.lines {
-        if i > 0 {
-            if _, err := writer.WriteString("\n"); err != nil {
-                file.Close()
-                os.Remove(tempFile)
-                return err
-            }
-        }
-        if _, err := writer.WriteString(line); err != nil {
-            file.Close()
-            os.Remove(tempFile)
-            return err
-        }
+    tempFile := b.filename + ".tmp"
+    file, err := os.Create(tempFile)
+    if err != nil {
+        return err
     }
 
-    if err := writer.Flush(); err != nil {
+    if _, err := file.Write(data); err != nil {
         file.Close()
         os.Remove(tempFile)
         return err
@@ -124,6 +143,24 @@ func (b *Buffer) Save() error {
     return nil
 }
 
+// changed marks the buffer as modified. Every change bumps version, so
+// caches derived from the text can tell when they are stale.
+func (b *Buffer) changed() {
+    b.modified = true
+    b.version++
+}
+
+func (b *Buffer) replaced(start, end, count int) {
+    b.lineEdits = append(b.lineEdits, lineEdit{start, end, count})
+}
+
+// takeLineEdits returns the line edits since the last call.
+func (b *Buffer) takeLineEdits() []lineEdit {
+    edits := b.lineEdits
+    b.lineEdits = nil
+    return edits
+}
+
 func (b *Buffer) GetLine(row int) string {
     if row < 0 || row >= len(b.lines) {
         return ""
@@ -154,7 +191,7 @@ func (b *Buffer) InsertChar(row, col int, ch rune) {
 
     newLine := line[:col] + string(ch) + line[col:]
     b.lines[row] = newLine
-    b.modified = true
+    b.changed()
 }
 
 func (b *Buffer) DeleteChar(row, col int) {
@@ -167,14 +204,16 @@ func (b *Buffer) DeleteChar(row, col int) {
         if col > len(line) {
             col = len(line)
         }
-        b.lines[row] = line[:col-1] + line[col:]
-        b.modified = true
+        _, size := utf8.DecodeLastRuneInString(line[:col])
+        b.lines[row] = line[:col-size] + line[col:]
+        b.changed()
     } else if row > 0 {
         prevLine := b.lines[row-1]
         currentLine := b.lines[row]
         b.lines[row-1] = prevLine + currentLine
         b.lines = append(b.lines[:row], b.lines[row+1:]...)
-        b.modified = true
+        b.replaced(row-1, row+1, 1)
+        b.changed()
     }
 }
 
@@ -185,8 +224,9 @@ func (b *Buffer) DeleteCharForward(row, col int) {
 
     line := b.lines[row]
     if col >= 0 && col < len(line) {
-        b.lines[row] = line[:col] + line[col+1:]
-        b.modified = true
+        _, size := utf8.DecodeRuneInString(line[col:])
+        b.lines[row] = line[:col] + line[col+size:]
+        b.changed()
     }
 }
 
@@ -212,8 +252,9 @@ func (b *Buffer) InsertNewline(row, col int) {
     newLines[row+1] = after
     copy(newLines[row+2:], b.lines[row+1:])
     b.lines = newLines
+    b.replaced(row, row+1, 2)
 
-    b.modified = true
+    b.changed()
 }
 
 func (b *Buffer) DeleteLine(row int) {
@@ -225,8 +266,17 @@ func (b *Buffer) DeleteLine(row int) {
         b.lines[0] = ""
     } else {
         b.lines = append(b.lines[:row], b.lines[row+1:]...)
+        b.replaced(row, row+1, 0)
     }
-    b.modified = true
+    b.changed()
+}
+
+func (b *Buffer) SetLine(row int, text string) {
+    if row < 0 || row >= len(b.lines) {
+        return
+    }
+    b.lines[row] = text
+    b.changed()
 }
 
 func (b *Buffer) AppendToLine(row int, text string) {
@@ -234,7 +284,7 @@ func (b *Buffer) AppendToLine(row int, text string) {
         return
     }
     b.lines[row] += text
-    b.modified = true
+    b.changed()
 }
 
 func (b *Buffer) InsertText(row, col int, text string) {
@@ -269,12 +319,123 @@ func (b *Buffer) InsertText(row, col int, text string) {
         newLines[row+len(lines)-1] = lines[len(lines)-1] + after
         copy(newLines[row+len(lines):], b.lines[row+1:])
         b.lines = newLines
+        b.replaced(row, row+1, len(lines))
     }
 
-    b.modified = true
+    b.changed()
+}
+
+func (b *Buffer) GetLines(start, end int) []string {
+    if start < 0 {
+        start = 0
+    }
+    if end > len(b.lines) {
+        end = len(b.lines)
+    }
+    if start >= end {
+        return []string{}
+    }
+    result := make([]string, end-start)
+    copy(result, b.lines[start:end])
+    return result
+}
+
+// ReplaceLines replaces lines [start, end) with the given lines. An empty
+// range inserts, an empty replacement deletes.
+func (b *Buffer) ReplaceLines(start, end int, lines []string) {
+    if start < 0 || start > len(b.lines) || end < start || end > len(b.lines) {
+        return
+    }
+
+    newLines := make([]string, 0, len(b.lines)-(end-start)+len(lines))
+    newLines = append(newLines, b.lines[:start]...)
+    newLines = append(newLines, lines...)
+    newLines = append(newLines, b.lines[end:]...)
+    count := len(lines)
+    if len(newLines) == 0 {
+        newLines = []string{""}
+        count = 1
+    }
+    b.lines = newLines
+    b.replaced(start, end, count)
+    b.changed()
+}
+
+func (b *Buffer) GetRange(startRow, startCol, endRow, endCol int) string {
+    if startRow < 0 || endRow >= len(b.lines) || startRow > endRow {
+        return ""
+    }
+
+    if startRow == endRow {
+        line := b.lines[startRow]
+        startCol, endCol = clampCol(line, startCol), clampCol(line, endCol)
+        if startCol >= endCol {
+            return ""
+        }
+        return line[startCol:endCol]
+    }
+
+    var sb strings.Builder
+    first := b.lines[startRow]
+    sb.WriteString(first[clampCol(first, startCol):])
+    for row := startRow + 1; row < endRow; row++ {
+        sb.WriteString("\n")
+        sb.WriteString(b.lines[row])
+    }
+    last := b.lines[endRow]
+    sb.WriteString("\n")
+    sb.WriteString(last[:clampCol(last, endCol)])
+    return sb.String()
+}
+
+func (b *Buffer) DeleteRange(startRow, startCol, endRow, endCol int) {
+    if startRow < 0 || endRow >= len(b.lines) || startRow > endRow {
+        return
+    }
+
+    first := b.lines[startRow]
+    last := b.lines[endRow]
+    startCol, endCol = clampCol(first, startCol), clampCol(last, endCol)
+    if startRow == endRow && startCol >= endCol {
+        return
+    }
+
+    b.ReplaceLines(startRow, endRow+1, []string{first[:startCol] + last[endCol:]})
+}
+
+func clampCol(line string, col int) int {
+    if col < 0 {
+        return 0
+    }
+    if col > len(line) {
+        return len(line)
+    }
+    return col
+}
+
+// BeginBatch groups all following edits into a single undo step until
+// the matching EndBatch.
+func (b *Buffer) BeginBatch() {
+    b.batchDepth++
+}
+
+func (b *Buffer) EndBatch(cursorRow, cursorCol int) {
+    if b.batchDepth == 0 {
+        return
+    }
+    b.batchDepth--
+    if b.batchDepth == 0 && b.batchDirty {
+        b.batchDirty = false
+        b.SaveState(cursorRow, cursorCol)
+    }
 }
 
 func (b *Buffer) SaveState(cursorRow, cursorCol int) {
+    if b.batchDepth > 0 {
+        b.batchDirty = true
+        return
+    }
+
     linesCopy := make([]string, len(b.lines))
     copy(linesCopy, b.lines)
 
@@ -292,6 +453,23 @@ func (b *Buffer) SaveState(cursorRow, cursorCol int) {
     b.redoStack = b.redoStack[:0]
 }
 
+// restore replaces the whole text with a copy of lines, recording only
+// the lines that differ as edited.
+func (b *Buffer) restore(lines []string) {
+    start := 0
+    for start < len(b.lines) && start < len(lines) && b.lines[start] == lines[start] {
+        start++
+    }
+    end, count := len(b.lines), len(lines)
+    for end > start && count > start && b.lines[end-1] == lines[count-1] {
+        end--
+        count--
+    }
+    b.replaced(start, end, count-start)
+    b.lines = make([]string, len(lines))
+    copy(b.lines, lines)
+}
+
 func (b *Buffer) Undo() (int, int, bool) {
     if len(b.undoStack) == 0 {
         return 0, 0, false
@@ -306,9 +484,8 @@ func (b *Buffer) Undo() (int, int, bool) {
     state := b.undoStack[len(b.undoStack)-1]
     b.undoStack = b.undoStack[:len(b.undoStack)-1]
 
-    b.lines = make([]string, len(state.lines))
-    copy(b.lines, state.lines)
-    b.modified = true
+    b.restore(state.lines)
+    b.changed()
 
     return state.cursorRow, state.cursorCol, true
 }
@@ -327,9 +504,8 @@ func (b *Buffer) Redo() (int, int, bool) {
     state := b.redoStack[len(b.redoStack)-1]
     b.redoStack = b.redoStack[:len(b.redoStack)-1]
 
-    b.lines = make([]string, len(state.lines))
-    copy(b.lines, state.lines)
-    b.modified = true
+    b.restore(state.lines)
+    b.changed()
 
     return state.cursorRow, state.cursorCol, true
 }
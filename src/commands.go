@@ -0,0 +1,329 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+type commandFunc func(e *Editor, tab *Tab, args []string) error
+
+// editorCommands lists the commands available from the command prompt
+// (Ctrl+E). Arguments are separated by spaces.
+func editorCommands() map[string]commandFunc {
+    return map[string]commandFunc{
+        "move-up": func(e *Editor, tab *Tab, args []string) error {
+            e.moveLines(tab, -1)
+            return nil
+        },
+        "move-down": func(e *Editor, tab *Tab, args []string) error {
+            e.moveLines(tab, 1)
+            return nil
+        },
+        "duplicate": func(e *Editor, tab *Tab, args []string) error {
+            e.duplicateLines(tab)
+            return nil
+        },
+        "join": func(e *Editor, tab *Tab, args []string) error {
+            e.joinLines(tab)
+            return nil
+        },
+        "delete-lines": func(e *Editor, tab *Tab, args []string) error {
+            e.deleteLines(tab)
+            return nil
+        },
+        "sort": func(e *Editor, tab *Tab, args []string) error {
+            mode := SortLexical
+            ignoreCase := false
+            for _, arg := range args {
+                switch arg {
+                case "lexical":
+                    mode = SortLexical
+                case "numeric", "-n":
+                    mode = SortNumeric
+                case "natural", "-v":
+                    mode = SortNatural
+                case "-i":
+                    ignoreCase = true
+                default:
+                    return fmt.Errorf("usage: sort [lexical|numeric|natural] [-i]")
+                }
+            }
+            e.sortLines(tab, mode, ignoreCase)
+            return nil
+        },
+        "reverse": func(e *Editor, tab *Tab, args []string) error {
+            e.reverseLines(tab)
+            return nil
+        },
+        "unique": func(e *Editor, tab *Tab, args []string) error {
+            e.uniqueLines(tab)
+            return nil
+        },
+        "shuffle": func(e *Editor, tab *Tab, args []string) error {
+            e.shuffleLines(tab)
+            return nil
+        },
+        "comment": func(e *Editor, tab *Tab, args []string) error {
+            e.toggleComment(tab)
+            return nil
+        },
+        "reflow": func(e *Editor, tab *Tab, args []string) error {
+            width := 0
+            if len(args) > 1 {
+                return fmt.Errorf("usage: reflow [WIDTH]")
+            }
+            if len(args) == 1 {
+                n, err := strconv.Atoi(args[0])
+                if err != nil || n < 1 {
+                    return fmt.Errorf("invalid width '%s'", args[0])
+                }
+                width = n
+            }
+            e.reflow(tab, width)
+            return nil
+        },
+        "auto-wrap": func(e *Editor, tab *Tab, args []string) error {
+            e.autoWrap = !e.autoWrap
+            if e.autoWrap {
+                e.setStatusMsg(fmt.Sprintf("Auto-wrap on (width %d)", e.wrapWidth(tab)))
+            } else {
+                e.setStatusMsg("Auto-wrap off")
+            }
+            return nil
+        },
+        "soft-wrap": func(e *Editor, tab *Tab, args []string) error {
+            e.toggleSoftWrap()
+            return nil
+        },
+        "fold": func(e *Editor, tab *Tab, args []string) error {
+            e.fold(tab)
+            return nil
+        },
+        "unfold": func(e *Editor, tab *Tab, args []string) error {
+            e.unfold(tab)
+            return nil
+        },
+        "fold-all": func(e *Editor, tab *Tab, args []string) error {
+            e.foldToLevel(tab, 0)
+            return nil
+        },
+        "unfold-all": func(e *Editor, tab *Tab, args []string) error {
+            e.unfoldAll(tab)
+            return nil
+        },
+        "fold-level": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) != 1 {
+                return fmt.Errorf("usage: fold-level N")
+            }
+            level, err := strconv.Atoi(args[0])
+            if err != nil || level < 0 {
+                return fmt.Errorf("invalid level '%s'", args[0])
+            }
+            e.foldToLevel(tab, level)
+            return nil
+        },
+        "inc": func(e *Editor, tab *Tab, args []string) error {
+            count, unit, err := parseStepArgs(args)
+            if err != nil {
+                return err
+            }
+            e.increment(tab, count, unit)
+            return nil
+        },
+        "dec": func(e *Editor, tab *Tab, args []string) error {
+            count, unit, err := parseStepArgs(args)
+            if err != nil {
+                return err
+            }
+            e.increment(tab, -count, unit)
+            return nil
+        },
+        "grep": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) == 0 {
+                return fmt.Errorf("usage: grep PATTERN")
+            }
+            query := strings.Join(args, " ")
+            e.addSearchHistory(query)
+            e.startGrep(query)
+            return nil
+        },
+        "undo-replace": func(e *Editor, tab *Tab, args []string) error {
+            return e.undoReplace()
+        },
+        "open": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) != 1 {
+                return fmt.Errorf("usage: open FILE")
+            }
+            return e.openFile(args[0])
+        },
+        "outline": func(e *Editor, tab *Tab, args []string) error {
+            e.toggleOutline(tab)
+            return nil
+        },
+        "symbol": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) != 1 {
+                return fmt.Errorf("usage: symbol NAME")
+            }
+            return e.jumpToSymbol(tab, args[0])
+        },
+        "rename": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) != 1 {
+                return fmt.Errorf("usage: rename NEWNAME")
+            }
+            e.rename(tab, args[0])
+            return nil
+        },
+        "format": func(e *Editor, tab *Tab, args []string) error {
+            e.formatDocument(tab)
+            return nil
+        },
+        "lsp-restart": func(e *Editor, tab *Tab, args []string) error {
+            return e.restartLanguageServer(tab)
+        },
+        "match-bracket": func(e *Editor, tab *Tab, args []string) error {
+            e.jumpToMatchingBracket(tab)
+            return nil
+        },
+        "snippet": func(e *Editor, tab *Tab, args []string) error {
+            e.expandSnippet(tab)
+            return nil
+        },
+        "snippets": func(e *Editor, tab *Tab, args []string) error {
+            lang := e.languageFor(tab)
+            triggers := e.snippetTriggers(lang)
+            if len(triggers) == 0 {
+                e.setStatusMsg(fmt.Sprintf("No snippets for %s", lang.Name))
+            } else {
+                e.setStatusMsg(fmt.Sprintf("Snippets (%s): %s", lang.Name, strings.Join(triggers, ", ")))
+            }
+            return nil
+        },
+        "macro": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) == 0 || len(args) > 2 {
+                return fmt.Errorf("usage: macro NAME [COUNT]")
+            }
+            if e.playingMacro {
+                return fmt.Errorf("a macro cannot play another macro")
+            }
+            count := 1
+            if len(args) == 2 {
+                n, err := strconv.Atoi(args[1])
+                if err != nil || n < 1 {
+                    return fmt.Errorf("invalid count '%s'", args[1])
+                }
+                count = n
+            }
+            e.playMacro(args[0], count)
+            return nil
+        },
+        "macro-lines": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) != 1 {
+                return fmt.Errorf("usage: macro-lines NAME")
+            }
+            if e.playingMacro {
+                return fmt.Errorf("a macro cannot play another macro")
+            }
+            e.playMacroOnLines(args[0])
+            return nil
+        },
+        "macros": func(e *Editor, tab *Tab, args []string) error {
+            names := e.macroNames()
+            if len(names) == 0 {
+                e.setStatusMsg("No macros recorded. Press F7 to record one")
+            } else {
+                e.setStatusMsg("Macros: " + strings.Join(names, ", "))
+            }
+            return nil
+        },
+        "macro-delete": func(e *Editor, tab *Tab, args []string) error {
+            if len(args) != 1 {
+                return fmt.Errorf("usage: macro-delete NAME")
+            }
+            if _, ok := e.macros[args[0]]; !ok {
+                return fmt.Errorf("no macro named '%s'", args[0])
+            }
+            delete(e.macros, args[0])
+            if err := saveMacros(e.macros); err != nil {
+                return err
+            }
+            e.setStatusMsg(fmt.Sprintf("Macro '%s' deleted", args[0]))
+            return nil
+        },
+    }
+}
+
+// parseStepArgs reads the optional count and date unit of inc and dec.
+func parseStepArgs(args []string) (int, string, error) {
+    count, unit := 1, ""
+    for _, arg := range args {
+        if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+            count = n
+            continue
+        }
+        switch u := strings.TrimSuffix(arg, "s"); u {
+        case "year", "month", "day", "hour", "minute", "second":
+            unit = u
+        default:
+            return 0, "", fmt.Errorf("usage: inc|dec [COUNT] [year|month|day|hour|minute|second]")
+        }
+    }
+    return count, unit, nil
+}
+
+func (e *Editor) runCommand(input string) {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil {
+        return
+    }
+
+    fields := strings.Fields(input)
+    if len(fields) == 0 {
+        e.setStatusMsg("No command entered")
+        return
+    }
+
+    commands := editorCommands()
+    cmd, ok := commands[fields[0]]
+    if !ok {
+        names := make([]string, 0, len(commands))
+        for name := range commands {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        e.setStatusMsg(fmt.Sprintf("Unknown command '%s'. Available: %s", fields[0], strings.Join(names, ", ")))
+        return
+    }
+
+    if err := cmd(e, tab, fields[1:]); err != nil {
+        e.actionFailed = true
+        e.setStatusMsg(fmt.Sprintf("%s: %v", fields[0], err))
+    }
+}
+
+func (e *Editor) handleCommandMode(ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.setStatusMsg("Command cancelled")
+    case tcell.KeyEnter:
+        e.mode = ModeNormal
+        e.runCommand(e.inputBuffer)
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg("Command: " + e.inputBuffer)
+    case tcell.KeyRune:
+        e.inputBuffer += string(ev.Rune())
+        e.setStatusMsg("Command: " + e.inputBuffer)
+    }
+    return true
+}
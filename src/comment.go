@@ -0,0 +1,133 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "strings"
+)
+
+// toggleComment comments or uncomments the current line or the selected
+// lines. Line comments are used when the language has them, otherwise
+// the lines are wrapped in a block comment.
+func (e *Editor) toggleComment(tab *Tab) {
+    lang := e.languageFor(tab)
+    start, end := tab.selectedLines()
+    lines := tab.buffer.GetLines(start, end)
+
+    var result []string
+    var msg string
+    switch {
+    case lang.LineComment != "":
+        result, msg = toggleLineComment(lines, lang.LineComment)
+    case len(lang.BlockComment) == 2:
+        result, msg = toggleBlockComment(lines, lang.BlockComment[0], lang.BlockComment[1])
+    default:
+        e.setStatusMsg(fmt.Sprintf("No comment syntax known for %s", lang.Name))
+        return
+    }
+
+    tab.buffer.ReplaceLines(start, end, result)
+
+    adjust := func(c *Cursor) {
+        if c.Row >= start && c.Row < end {
+            c.Col = shiftCol(lines[c.Row-start], result[c.Row-start], c.Col)
+        }
+    }
+    adjust(tab.cursor)
+    if tab.selActive {
+        adjust(&tab.selAnchor)
+    }
+    e.commitLines(tab, msg)
+}
+
+// shiftCol maps a column in oldLine to the same text position in newLine
+// after comment markers were added or removed around it.
+func shiftCol(oldLine, newLine string, col int) int {
+    col = clampCol(oldLine, col)
+    rest := oldLine[col:]
+    if rest == "" {
+        return len(newLine)
+    }
+    if i := strings.Index(newLine, rest); i >= 0 {
+        return i
+    }
+    return clampCol(newLine, col+len(newLine)-len(oldLine))
+}
+
+func toggleLineComment(lines []string, prefix string) ([]string, string) {
+    indent := -1
+    commented := true
+    for _, line := range lines {
+        if isBlank(line) {
+            continue
+        }
+        ws := leadingWhitespace(line)
+        if indent < 0 || len(ws) < indent {
+            indent = len(ws)
+        }
+        if !strings.HasPrefix(line[len(ws):], prefix) {
+            commented = false
+        }
+    }
+    if indent < 0 {
+        return lines, "Nothing to comment"
+    }
+
+    result := make([]string, len(lines))
+    for i, line := range lines {
+        if isBlank(line) {
+            result[i] = line
+            continue
+        }
+        if commented {
+            ws := leadingWhitespace(line)
+            rest := strings.TrimPrefix(line[len(ws):], prefix)
+            rest = strings.TrimPrefix(rest, " ")
+            result[i] = ws + rest
+        } else {
+            result[i] = line[:indent] + prefix + " " + line[indent:]
+        }
+    }
+
+    if commented {
+        return result, fmt.Sprintf("Uncommented %d line(s)", len(lines))
+    }
+    return result, fmt.Sprintf("Commented %d line(s)", len(lines))
+}
+
+func toggleBlockComment(lines []string, open, close string) ([]string, string) {
+    first, last := -1, -1
+    for i, line := range lines {
+        if !isBlank(line) {
+            if first < 0 {
+                first = i
+            }
+            last = i
+        }
+    }
+    if first < 0 {
+        return lines, "Nothing to comment"
+    }
+
+    result := make([]string, len(lines))
+    copy(result, lines)
+
+    head := result[first]
+    ws := leadingWhitespace(head)
+    tail := strings.TrimRight(result[last], " \t")
+    if strings.HasPrefix(head[len(ws):], open) && strings.HasSuffix(tail, close) {
+        rest := strings.TrimPrefix(head[len(ws):], open)
+        result[first] = ws + strings.TrimPrefix(rest, " ")
+        tail = strings.TrimRight(result[last], " \t")
+        tail = strings.TrimSuffix(tail, close)
+        result[last] = strings.TrimSuffix(tail, " ")
+        return result, fmt.Sprintf("Uncommented %d line(s)", len(lines))
+    }
+
+    result[first] = ws + open + " " + head[len(ws):]
+    result[last] = strings.TrimRight(result[last], " \t") + " " + close
+    return result, fmt.Sprintf("Commented %d line(s)", len(lines))
+}
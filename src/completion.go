@@ -0,0 +1,252 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const maxCompletions = 50
+
+// completionState is an open completion popup. lspItems holds the
+// server's completions when they came from a language server; typing
+// then filters them instead of the buffer words.
+type completionState struct {
+    popup    *Popup
+    row      int
+    start    int
+    lspItems []PopupItem
+}
+
+type wordStats struct {
+    freq    int
+    minDist int
+    local   bool
+}
+
+// collectWords counts the words of all open buffers. Words in the active
+// buffer also remember their smallest line distance to the cursor.
+func (e *Editor) collectWords(tab *Tab, lang *Language) map[string]*wordStats {
+    words := make(map[string]*wordStats)
+    for _, t := range e.tabManager.tabs {
+        local := t == tab
+        for row := 0; row < t.buffer.LineCount(); row++ {
+            line := t.buffer.GetLine(row)
+            dist := row - tab.cursor.Row
+            if dist < 0 {
+                dist = -dist
+            }
+            for col := 0; col < len(line); {
+                r, size := utf8.DecodeRuneInString(line[col:])
+                if !lang.isWordChar(r) {
+                    col += size
+                    continue
+                }
+                start := col
+                for col < len(line) {
+                    r, size = utf8.DecodeRuneInString(line[col:])
+                    if !lang.isWordChar(r) {
+                        break
+                    }
+                    col += size
+                }
+                if local && row == tab.cursor.Row && start <= tab.cursor.Col && tab.cursor.Col <= col {
+                    continue
+                }
+
+                word := line[start:col]
+                ws := words[word]
+                if ws == nil {
+                    ws = &wordStats{minDist: -1}
+                    words[word] = ws
+                }
+                ws.freq++
+                if local && (ws.minDist < 0 || dist < ws.minDist) {
+                    ws.minDist = dist
+                    ws.local = true
+                }
+            }
+        }
+    }
+    return words
+}
+
+// completionCandidates returns the words starting with prefix, best
+// first. Nearby words in the current buffer rank above frequent ones.
+func (e *Editor) completionCandidates(tab *Tab, prefix string) []PopupItem {
+    lang := e.languageFor(tab)
+    words := e.collectWords(tab, lang)
+    lowerPrefix := strings.ToLower(prefix)
+
+    type candidate struct {
+        word  string
+        score float64
+        stats *wordStats
+    }
+    var cands []candidate
+    for word, ws := range words {
+        if len(word) <= len(prefix) || !strings.HasPrefix(strings.ToLower(word), lowerPrefix) {
+            continue
+        }
+        score := float64(ws.freq)
+        if ws.local {
+            score += 100.0 / float64(1+ws.minDist)
+        }
+        if strings.HasPrefix(word, prefix) {
+            score += 10
+        }
+        cands = append(cands, candidate{word, score, ws})
+    }
+
+    sort.Slice(cands, func(i, j int) bool {
+        if cands[i].score != cands[j].score {
+            return cands[i].score > cands[j].score
+        }
+        return cands[i].word < cands[j].word
+    })
+    if len(cands) > maxCompletions {
+        cands = cands[:maxCompletions]
+    }
+
+    items := make([]PopupItem, len(cands))
+    for i, c := range cands {
+        detail := fmt.Sprintf("%dx", c.stats.freq)
+        if !c.stats.local {
+            detail += " other tab"
+        }
+        items[i] = PopupItem{Label: c.word, Detail: detail}
+    }
+    return items
+}
+
+func (e *Editor) completionPrefix(tab *Tab) (int, string) {
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    col := clampCol(line, tab.cursor.Col)
+    lang := e.languageFor(tab)
+
+    start := col
+    for start > 0 {
+        r, size := utf8.DecodeLastRuneInString(line[:start])
+        if !lang.isWordChar(r) {
+            break
+        }
+        start -= size
+    }
+    return start, line[start:col]
+}
+
+// openCompletion shows the popup for the word before the cursor. When
+// explicit is false it only opens once the prefix is long enough.
+func (e *Editor) openCompletion(tab *Tab, explicit bool) {
+    start, prefix := e.completionPrefix(tab)
+    if !explicit && (e.config.CompletionAutoChars <= 0 || len(prefix) < e.config.CompletionAutoChars) {
+        e.completion = nil
+        return
+    }
+
+    items := e.completionCandidates(tab, prefix)
+    if len(items) == 0 {
+        e.completion = nil
+        if explicit {
+            e.setStatusMsg(fmt.Sprintf("No completions for '%s'", prefix))
+        }
+        return
+    }
+
+    if e.completion == nil {
+        e.completion = &completionState{popup: NewPopup(nil, 8)}
+    }
+    e.completion.popup.SetItems(items)
+    e.completion.row = tab.cursor.Row
+    e.completion.start = start
+}
+
+// refreshCompletion refilters an open popup after typing, or opens it
+// automatically when enabled.
+func (e *Editor) refreshCompletion(tab *Tab, typed bool) {
+    if e.completion == nil {
+        if typed {
+            e.openCompletion(tab, false)
+        }
+        return
+    }
+
+    start, prefix := e.completionPrefix(tab)
+    if tab.cursor.Row != e.completion.row || start != e.completion.start || prefix == "" {
+        e.completion = nil
+        return
+    }
+    items := e.completionCandidates(tab, prefix)
+    if e.completion.lspItems != nil {
+        items = filterCompletions(e.completion.lspItems, prefix)
+    }
+    if len(items) == 0 {
+        e.completion = nil
+        return
+    }
+    e.completion.popup.SetItems(items)
+}
+
+func (e *Editor) acceptCompletion(tab *Tab) {
+    item, ok := e.completion.popup.Current()
+    start := e.completion.start
+    e.completion = nil
+    if !ok {
+        return
+    }
+
+    text := item.Label
+    if item.Insert != "" {
+        text = item.Insert
+    }
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    col := clampCol(line, tab.cursor.Col)
+    tab.buffer.SetLine(tab.cursor.Row, line[:start]+text+line[col:])
+    tab.cursor.Col = start + len(text)
+    tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+    e.quitAttempts = 0
+}
+
+// handleCompletionKey lets the popup take navigation keys. Other keys
+// are passed on; typing refilters the list afterwards.
+func (e *Editor) handleCompletionKey(tab *Tab, ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyUp:
+        e.completion.popup.Move(-1)
+        return true
+    case tcell.KeyDown:
+        e.completion.popup.Move(1)
+        return true
+    case tcell.KeyPgUp:
+        e.completion.popup.Move(-e.completion.popup.MaxRows)
+        return true
+    case tcell.KeyPgDn:
+        e.completion.popup.Move(e.completion.popup.MaxRows)
+        return true
+    case tcell.KeyEnter, tcell.KeyTab:
+        e.acceptCompletion(tab)
+        return true
+    case tcell.KeyEscape:
+        e.completion = nil
+        return true
+    case tcell.KeyRune, tcell.KeyBackspace, tcell.KeyBackspace2:
+        return false
+    }
+    e.completion = nil
+    return false
+}
+
+func (e *Editor) renderCompletion(tab *Tab, screenX, screenY int) {
+    if e.completion == nil {
+        return
+    }
+    x := screenX - (tab.cursor.Col - e.completion.start) - 1
+    e.completion.popup.Draw(e.screen, x, screenY, 1, e.height, e.width)
+}
@@ -0,0 +1,58 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+)
+
+type Config struct {
+    Languages           map[string]*Language `json:"languages"`
+    AutoClose           *bool                `json:"auto_close"`
+    CompletionAutoChars int                  `json:"completion_auto_chars"`
+    WrapWidth           int                  `json:"wrap_width"`
+    AutoWrap            bool                 `json:"auto_wrap"`
+    SoftWrap            bool                 `json:"soft_wrap"`
+    ScrollMargin        int                  `json:"scroll_margin"`
+    FormatOnSave        bool                 `json:"format_on_save"`
+}
+
+func (c *Config) autoClose() bool {
+    return c.AutoClose == nil || *c.AutoClose
+}
+
+func configDir() string {
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        dir = os.TempDir()
+    }
+    return filepath.Join(dir, "goedit")
+}
+
+func defaultConfigPath() string {
+    return filepath.Join(configDir(), "config.json")
+}
+
+// LoadConfig reads the JSON config file at path. A missing file is not
+// an error and yields the defaults.
+func LoadConfig(path string) (*Config, error) {
+    cfg := &Config{}
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        if os.IsNotExist(err) {
+            return cfg, nil
+        }
+        return cfg, err
+    }
+
+    if err := json.Unmarshal(data, cfg); err != nil {
+        return &Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+    }
+    return cfg, nil
+}
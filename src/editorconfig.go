@@ -0,0 +1,348 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode/utf16"
+)
+
+// EditorConfig holds the .editorconfig properties that apply to one
+// file. Zero values mean the property is not set.
+type EditorConfig struct {
+    IndentStyle            string
+    IndentSize             int
+    TabWidth               int
+    EndOfLine              string
+    Charset                string
+    TrimTrailingWhitespace bool
+    InsertFinalNewline     *bool
+    MaxLineLength          int
+}
+
+type editorConfigSection struct {
+    pattern *regexp.Regexp
+    props   map[string]string
+}
+
+// findEditorConfig collects the .editorconfig files from the file's
+// directory up to the first one marked root = true and returns the
+// merged properties for the file, or nil if none apply.
+func findEditorConfig(filename string) *EditorConfig {
+    if filename == "" {
+        return nil
+    }
+    path, err := filepath.Abs(filename)
+    if err != nil {
+        return nil
+    }
+
+    var files []string
+    for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+        name := filepath.Join(dir, ".editorconfig")
+        if root, ok := editorConfigIsRoot(name); ok {
+            files = append(files, name)
+            if root {
+                break
+            }
+        }
+        if filepath.Dir(dir) == dir {
+            break
+        }
+    }
+
+    props := make(map[string]string)
+    for i := len(files) - 1; i >= 0; i-- {
+        sections, err := parseEditorConfig(files[i])
+        if err != nil {
+            continue
+        }
+        rel, err := filepath.Rel(filepath.Dir(files[i]), path)
+        if err != nil {
+            continue
+        }
+        rel = filepath.ToSlash(rel)
+        for _, s := range sections {
+            if s.pattern.MatchString(rel) {
+                for k, v := range s.props {
+                    props[k] = v
+                }
+            }
+        }
+    }
+    if len(props) == 0 {
+        return nil
+    }
+    return newEditorConfig(props)
+}
+
+func editorConfigIsRoot(name string) (root, ok bool) {
+    file, err := os.Open(name)
+    if err != nil {
+        return false, false
+    }
+    defer file.Close()
+
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        line := strings.TrimSpace(scanner.Text())
+        if strings.HasPrefix(line, "[") {
+            break
+        }
+        if k, v, found := strings.Cut(line, "="); found && strings.EqualFold(strings.TrimSpace(k), "root") {
+            root = strings.EqualFold(strings.TrimSpace(v), "true")
+        }
+    }
+    return root, true
+}
+
+func parseEditorConfig(name string) ([]editorConfigSection, error) {
+    file, err := os.Open(name)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    var sections []editorConfigSection
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" || line[0] == '#' || line[0] == ';' {
+            continue
+        }
+        if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+            re, err := editorConfigGlob(line[1 : len(line)-1])
+            if err != nil {
+                return nil, fmt.Errorf("%s: %w", name, err)
+            }
+            sections = append(sections, editorConfigSection{pattern: re, props: map[string]string{}})
+            continue
+        }
+        k, v, found := strings.Cut(line, "=")
+        if !found || len(sections) == 0 {
+            continue
+        }
+        key := strings.ToLower(strings.TrimSpace(k))
+        sections[len(sections)-1].props[key] = strings.ToLower(strings.TrimSpace(v))
+    }
+    return sections, scanner.Err()
+}
+
+// editorConfigGlob turns a section name into a regular expression over
+// slash-separated paths relative to the .editorconfig file. Patterns
+// without a slash match the file name in any subdirectory.
+func editorConfigGlob(glob string) (*regexp.Regexp, error) {
+    if !strings.Contains(glob, "/") {
+        glob = "**/" + glob
+    }
+    glob = strings.TrimPrefix(glob, "/")
+
+    var sb strings.Builder
+    sb.WriteString("^")
+    braces := 0
+    for i := 0; i < len(glob); i++ {
+        c := glob[i]
+        switch c {
+        case '*':
+            if strings.HasPrefix(glob[i:], "**/") {
+                sb.WriteString("(?:.*/)?")
+                i += 2
+            } else if strings.HasPrefix(glob[i:], "**") {
+                sb.WriteString(".*")
+                i++
+            } else {
+                sb.WriteString("[^/]*")
+            }
+        case '?':
+            sb.WriteString("[^/]")
+        case '[':
+            end := strings.IndexByte(glob[i:], ']')
+            if end < 0 {
+                sb.WriteString(`\[`)
+                continue
+            }
+            class := glob[i+1 : i+end]
+            if strings.HasPrefix(class, "!") {
+                class = "^" + class[1:]
+            }
+            sb.WriteString("[" + class + "]")
+            i += end
+        case '{':
+            end := strings.IndexByte(glob[i:], '}')
+            if end > 0 {
+                if alt, ok := numericRange(glob[i+1 : i+end]); ok {
+                    sb.WriteString(alt)
+                    i += end
+                    continue
+                }
+            }
+            braces++
+            sb.WriteString("(?:")
+        case '}':
+            if braces == 0 {
+                sb.WriteString(`\}`)
+                continue
+            }
+            braces--
+            sb.WriteString(")")
+        case ',':
+            if braces > 0 {
+                sb.WriteString("|")
+            } else {
+                sb.WriteString(",")
+            }
+        case '\\':
+            if i+1 < len(glob) {
+                i++
+                sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+            }
+        default:
+            sb.WriteString(regexp.QuoteMeta(string(c)))
+        }
+    }
+    sb.WriteString("$")
+    return regexp.Compile(sb.String())
+}
+
+// numericRange expands "{n1..n2}" into an alternation of the numbers.
+func numericRange(body string) (string, bool) {
+    from, to, found := strings.Cut(body, "..")
+    if !found {
+        return "", false
+    }
+    lo, err1 := strconv.Atoi(from)
+    hi, err2 := strconv.Atoi(to)
+    if err1 != nil || err2 != nil || hi < lo || hi-lo > 1000 {
+        return "", false
+    }
+    nums := make([]string, 0, hi-lo+1)
+    for n := lo; n <= hi; n++ {
+        nums = append(nums, strconv.Itoa(n))
+    }
+    return "(?:" + strings.Join(nums, "|") + ")", true
+}
+
+func newEditorConfig(props map[string]string) *EditorConfig {
+    ec := &EditorConfig{}
+    get := func(key string) string {
+        v := props[key]
+        if v == "unset" {
+            return ""
+        }
+        return v
+    }
+
+    ec.IndentStyle = get("indent_style")
+    ec.TabWidth, _ = strconv.Atoi(get("tab_width"))
+    if size := get("indent_size"); size == "tab" {
+        ec.IndentSize = ec.TabWidth
+    } else {
+        ec.IndentSize, _ = strconv.Atoi(size)
+    }
+    if ec.TabWidth == 0 {
+        ec.TabWidth = ec.IndentSize
+    }
+    ec.EndOfLine = get("end_of_line")
+    ec.Charset = get("charset")
+    ec.TrimTrailingWhitespace = get("trim_trailing_whitespace") == "true"
+    if v := get("insert_final_newline"); v == "true" || v == "false" {
+        b := v == "true"
+        ec.InsertFinalNewline = &b
+    }
+    ec.MaxLineLength, _ = strconv.Atoi(get("max_line_length"))
+    return ec
+}
+
+// indentUnit returns the text for one indentation level, starting from
+// the language's default.
+func (ec *EditorConfig) indentUnit(unit string) string {
+    switch {
+    case ec.IndentStyle == "tab":
+        return "\t"
+    case ec.IndentSize > 0 && (ec.IndentStyle == "space" || unit != "\t"):
+        return strings.Repeat(" ", ec.IndentSize)
+    case ec.IndentStyle == "space" && unit == "\t":
+        return defaultIndentUnit
+    }
+    return unit
+}
+
+func (ec *EditorConfig) lineEnding() string {
+    switch ec.EndOfLine {
+    case "crlf":
+        return "\r\n"
+    case "cr":
+        return "\r"
+    }
+    return "\n"
+}
+
+// decodeText converts file contents in the given charset to UTF-8.
+func decodeText(data []byte, charset string) (string, error) {
+    switch charset {
+    case "", "utf-8", "utf-8-bom":
+        return strings.TrimPrefix(string(data), "\uFEFF"), nil
+    case "latin1":
+        runes := make([]rune, len(data))
+        for i, c := range data {
+            runes[i] = rune(c)
+        }
+        return string(runes), nil
+    case "utf-16be", "utf-16le":
+        if len(data)%2 != 0 {
+            return "", fmt.Errorf("odd length for %s", charset)
+        }
+        units := make([]uint16, len(data)/2)
+        for i := range units {
+            if charset == "utf-16be" {
+                units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+            } else {
+                units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+            }
+        }
+        if len(units) > 0 && units[0] == 0xFEFF {
+            units = units[1:]
+        }
+        return string(utf16.Decode(units)), nil
+    }
+    return "", fmt.Errorf("unsupported charset '%s'", charset)
+}
+
+// encodeText converts UTF-8 text to the given charset.
+func encodeText(text, charset string) ([]byte, error) {
+    switch charset {
+    case "", "utf-8":
+        return []byte(text), nil
+    case "utf-8-bom":
+        return append([]byte("\uFEFF"), text...), nil
+    case "latin1":
+        data := make([]byte, 0, len(text))
+        for _, r := range text {
+            if r > 0xFF {
+                return nil, fmt.Errorf("character %q cannot be written as latin1", r)
+            }
+            data = append(data, byte(r))
+        }
+        return data, nil
+    case "utf-16be", "utf-16le":
+        units := utf16.Encode(append([]rune{0xFEFF}, []rune(text)...))
+        data := make([]byte, 0, 2*len(units))
+        for _, u := range units {
+            if charset == "utf-16be" {
+                data = append(data, byte(u>>8), byte(u))
+            } else {
+                data = append(data, byte(u), byte(u>>8))
+            }
+        }
+        return data, nil
+    }
+    return nil, fmt.Errorf("unsupported charset '%s'", charset)
+}
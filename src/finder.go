@@ -0,0 +1,407 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const (
+    maxRecentFiles   = 50
+    maxFinderMatches = 1000
+)
+
+type finderMatch struct {
+    path      string
+    score     int
+    positions []int
+}
+
+// fileFinder is the Ctrl+P overlay. The file list is filled by a
+// background walk of the working directory while the overlay is open.
+type fileFinder struct {
+    mu       sync.Mutex
+    files    []string
+    done     bool
+    cancel   chan struct{}
+    query    string
+    matches  []finderMatch
+    ranked   string
+    rankedN  int
+    selected int
+    top      int
+    previews map[string][]string
+}
+
+func (f *fileFinder) stop() {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if !f.done {
+        f.done = true
+        close(f.cancel)
+    }
+}
+
+// fuzzyScore matches query as a subsequence of path, ignoring case. The
+// score favours consecutive characters, characters at the start of a
+// word and matches in the file name. ok is false when query does not
+// match at all.
+func fuzzyScore(query, path string) (score int, positions []int, ok bool) {
+    if query == "" {
+        return 0, nil, true
+    }
+    q := []rune(strings.ToLower(query))
+    base := strings.LastIndexByte(path, '/') + 1
+
+    best := -1 << 31
+    for start, r := range path {
+        if unicode.ToLower(r) != q[0] {
+            continue
+        }
+        s, pos, matched := fuzzyAlign(q, path, start, base)
+        if matched && s > best {
+            best, positions, ok = s, pos, true
+        }
+    }
+    if !ok {
+        return 0, nil, false
+    }
+    return best - len(path)/8, positions, true
+}
+
+// fuzzyAlign greedily matches q in path from byte offset start.
+func fuzzyAlign(q []rune, path string, start, base int) (int, []int, bool) {
+    score, qi, last := 0, 0, -1
+    var positions []int
+    prev := rune(0)
+    if start > 0 {
+        prev, _ = utf8.DecodeLastRuneInString(path[:start])
+    }
+    for i, r := range path[start:] {
+        i += start
+        if qi < len(q) && unicode.ToLower(r) == q[qi] {
+            score++
+            if last >= 0 && i == last+utf8.RuneLen(prev) {
+                score += 6
+            } else if last >= 0 {
+                gap := i - last
+                if gap > 3 {
+                    gap = 3
+                }
+                score -= gap
+            }
+            if i == 0 || strings.ContainsRune("/_-. ", prev) || unicode.IsLower(prev) && unicode.IsUpper(r) {
+                score += 4
+            }
+            if i == base {
+                score += 10
+            }
+            if i >= base {
+                score += 2
+            }
+            positions = append(positions, i)
+            last = i
+            qi++
+        }
+        prev = r
+    }
+    return score, positions, qi == len(q)
+}
+
+func recentFilesPath() string {
+    return filepath.Join(configDir(), "recent_files.json")
+}
+
+func loadRecentFiles() []string {
+    var files []string
+    data, err := os.ReadFile(recentFilesPath())
+    if err != nil {
+        return nil
+    }
+    if err := json.Unmarshal(data, &files); err != nil {
+        return nil
+    }
+    return files
+}
+
+// addRecentFile moves filename to the front of the recently opened
+// files and saves the list.
+func (e *Editor) addRecentFile(filename string) {
+    path, err := filepath.Abs(filename)
+    if err != nil {
+        return
+    }
+    recent := []string{path}
+    for _, f := range e.recentFiles {
+        if f != path && len(recent) < maxRecentFiles {
+            recent = append(recent, f)
+        }
+    }
+    e.recentFiles = recent
+
+    data, err := json.MarshalIndent(recent, "", "  ")
+    if err == nil {
+        if err = os.MkdirAll(configDir(), 0755); err == nil {
+            err = os.WriteFile(recentFilesPath(), data, 0644)
+        }
+    }
+    if err != nil {
+        e.setStatusMsg(fmt.Sprintf("Failed to save recent files: %v", err))
+    }
+}
+
+// openFile shows filename in its tab, opening it if needed.
+func (e *Editor) openFile(filename string) error {
+    if err := e.tabManager.OpenFile(filename); err != nil {
+        return err
+    }
+    e.addRecentFile(filename)
+    e.setStatusMsg(fmt.Sprintf("Opened %s (Tab %d/%d)", filename, e.tabManager.activeTab+1, e.tabManager.GetTabCount()))
+    return nil
+}
+
+func (e *Editor) startFinder() {
+    root, err := os.Getwd()
+    if err != nil {
+        e.setStatusMsg(fmt.Sprintf("Cannot list files: %v", err))
+        return
+    }
+
+    f := &fileFinder{cancel: make(chan struct{}), rankedN: -1, previews: make(map[string][]string)}
+    e.finder = f
+    e.mode = ModeFinder
+
+    files := make(chan string)
+    go func() {
+        walkProject(root, files, f.cancel)
+        close(files)
+    }()
+    go func() {
+        for path := range files {
+            if rel, err := filepath.Rel(root, path); err == nil {
+                path = rel
+            }
+            f.mu.Lock()
+            f.files = append(f.files, path)
+            n := len(f.files)
+            f.mu.Unlock()
+            if n%500 == 0 {
+                e.redraw()
+            }
+        }
+        f.mu.Lock()
+        if !f.done {
+            f.done = true
+            close(f.cancel)
+        }
+        f.mu.Unlock()
+        e.redraw()
+    }()
+}
+
+// rankFiles scores the indexed files against the query. Recently opened
+// files get a bonus, so with an empty query they come first.
+func (e *Editor) rankFiles(f *fileFinder) {
+    f.mu.Lock()
+    files := f.files
+    f.mu.Unlock()
+    if f.ranked == f.query && f.rankedN == len(files) {
+        return
+    }
+
+    recent := make(map[string]int)
+    for i, path := range e.recentFiles {
+        recent[path] = len(e.recentFiles) - i
+    }
+    cwd, _ := os.Getwd()
+
+    f.matches = f.matches[:0]
+    for _, path := range files {
+        score, positions, ok := fuzzyScore(f.query, path)
+        if !ok {
+            continue
+        }
+        score += 2 * recent[filepath.Join(cwd, path)]
+        f.matches = append(f.matches, finderMatch{path, score, positions})
+    }
+    sort.SliceStable(f.matches, func(i, j int) bool {
+        a, b := f.matches[i], f.matches[j]
+        if a.score != b.score {
+            return a.score > b.score
+        }
+        if len(a.path) != len(b.path) {
+            return len(a.path) < len(b.path)
+        }
+        return a.path < b.path
+    })
+    if len(f.matches) > maxFinderMatches {
+        f.matches = f.matches[:maxFinderMatches]
+    }
+    if f.ranked != f.query {
+        f.selected = 0
+    }
+    f.ranked, f.rankedN = f.query, len(files)
+}
+
+func (e *Editor) handleFinderMode(ev *tcell.EventKey) bool {
+    f := e.finder
+    if f == nil {
+        e.mode = ModeNormal
+        return true
+    }
+
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        f.stop()
+        e.finder = nil
+        e.mode = ModeNormal
+        e.setStatusMsg("")
+    case tcell.KeyEnter:
+        // Open the highlighted file: re-rank only if the list on screen
+        // is for another query, not because more files were indexed.
+        if f.ranked != f.query || f.rankedN < 0 {
+            e.rankFiles(f)
+        }
+        if f.selected >= len(f.matches) {
+            return true
+        }
+        f.stop()
+        e.finder = nil
+        e.mode = ModeNormal
+        if err := e.openFile(f.matches[f.selected].path); err != nil {
+            e.setStatusMsg(fmt.Sprintf("Failed to open %s: %v", f.matches[f.selected].path, err))
+        }
+    case tcell.KeyUp:
+        f.selected--
+    case tcell.KeyDown:
+        f.selected++
+    case tcell.KeyPgUp:
+        f.selected -= e.height
+    case tcell.KeyPgDn:
+        f.selected += e.height
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(f.query) > 0 {
+            _, size := utf8.DecodeLastRuneInString(f.query)
+            f.query = f.query[:len(f.query)-size]
+        }
+    case tcell.KeyRune:
+        f.query += string(ev.Rune())
+    }
+    if f.selected >= len(f.matches) {
+        f.selected = len(f.matches) - 1
+    }
+    if f.selected < 0 {
+        f.selected = 0
+    }
+    return true
+}
+
+// filePreview returns the first lines of path for the preview pane.
+func (f *fileFinder) filePreview(path string, lines int) []string {
+    if preview, ok := f.previews[path]; ok {
+        return preview
+    }
+    var preview []string
+    info, err := os.Stat(path)
+    switch {
+    case err != nil:
+        preview = []string{err.Error()}
+    case info.Size() > maxGrepFileSize:
+        preview = []string{"(large file)"}
+    default:
+        data, err := os.ReadFile(path)
+        if err != nil {
+            preview = []string{err.Error()}
+        } else if isBinary(data) {
+            preview = []string{"(binary file)"}
+        } else {
+            preview = strings.SplitN(string(data), "\n", lines+1)
+            if len(preview) > lines {
+                preview = preview[:lines]
+            }
+        }
+    }
+    f.previews[path] = preview
+    return preview
+}
+
+// renderFinder draws the ranked files on the left and a preview of the
+// highlighted file on the right.
+func (e *Editor) renderFinder() {
+    f := e.finder
+    e.rankFiles(f)
+    f.mu.Lock()
+    total, done := len(f.files), f.done
+    f.mu.Unlock()
+
+    if f.selected < f.top {
+        f.top = f.selected
+    }
+    if f.selected >= f.top+e.height {
+        f.top = f.selected - e.height + 1
+    }
+
+    listWidth := e.width / 2
+    if listWidth < 20 {
+        listWidth = e.width
+    }
+    for y := 0; y < e.height && f.top+y < len(f.matches); y++ {
+        m := f.matches[f.top+y]
+        style := tcell.StyleDefault
+        if f.top+y == f.selected {
+            style = style.Reverse(true)
+        }
+        hit := make(map[int]bool, len(m.positions))
+        for _, p := range m.positions {
+            hit[p] = true
+        }
+        x := 0
+        for i, r := range m.path {
+            if x >= listWidth-1 {
+                break
+            }
+            cs := style
+            if hit[i] {
+                cs = cs.Foreground(tcell.ColorYellow).Bold(true)
+            }
+            e.screen.SetContent(x, y+1, r, nil, cs)
+            x++
+        }
+    }
+
+    if listWidth < e.width && f.selected < len(f.matches) {
+        sep := tcell.StyleDefault.Foreground(tcell.ColorGray)
+        for y := 0; y < e.height; y++ {
+            e.screen.SetContent(listWidth, y+1, '│', nil, sep)
+        }
+        for y, line := range f.filePreview(f.matches[f.selected].path, e.height) {
+            line = strings.ReplaceAll(strings.TrimRight(line, "\r"), "\t", "    ")
+            x := listWidth + 2
+            for _, r := range line {
+                if x >= e.width {
+                    break
+                }
+                e.screen.SetContent(x, y+1, r, nil, tcell.StyleDefault)
+                x++
+            }
+        }
+    }
+
+    status := fmt.Sprintf("Open file: %s  (%d of %d files", f.query, len(f.matches), total)
+    if !done {
+        status += ", indexing"
+    }
+    e.setStatusMsg(status + ") | Enter: open, Esc: cancel")
+}
@@ -0,0 +1,303 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+const (
+    foldMarkerOpen  = "{{{"
+    foldMarkerClose = "}}}"
+)
+
+// foldRange is a foldable region. The start line stays visible and
+// summarises the lines up to and including end.
+type foldRange struct {
+    start int
+    end   int
+}
+
+// closedFoldAt returns the outermost closed fold whose first line is row.
+func (t *Tab) closedFoldAt(row int) *foldRange {
+    var found *foldRange
+    for i := range t.folds {
+        f := &t.folds[i]
+        if f.start == row && (found == nil || f.end > found.end) {
+            found = f
+        }
+    }
+    return found
+}
+
+// hidingFold returns the outermost closed fold that hides row.
+func (t *Tab) hidingFold(row int) *foldRange {
+    var found *foldRange
+    for i := range t.folds {
+        f := &t.folds[i]
+        if f.start < row && row <= f.end && (found == nil || f.start < found.start) {
+            found = f
+        }
+    }
+    return found
+}
+
+// nextVisibleRow returns the next line in direction dir that is not
+// hidden by a fold, or -1 if there is none.
+func (t *Tab) nextVisibleRow(row, dir int) int {
+    row += dir
+    for row >= 0 && row < t.buffer.LineCount() {
+        f := t.hidingFold(row)
+        if f == nil {
+            return row
+        }
+        if dir > 0 {
+            row = f.end + 1
+        } else {
+            row = f.start
+        }
+    }
+    return -1
+}
+
+// revealRow opens every fold that hides row.
+func (t *Tab) revealRow(row int) {
+    kept := t.folds[:0]
+    for _, f := range t.folds {
+        if !(f.start < row && row <= f.end) {
+            kept = append(kept, f)
+        }
+    }
+    t.folds = kept
+}
+
+// adjustFolds moves the folds along after lines [start, end) were
+// replaced by count lines. Folds that only partly overlap the edited
+// lines, or whose first line was deleted, no longer match the text and
+// are dropped.
+func (t *Tab) adjustFolds(start, end, count int) {
+    if len(t.folds) == 0 {
+        return
+    }
+    delta := count - (end - start)
+    kept := t.folds[:0]
+    for _, f := range t.folds {
+        switch {
+        case f.end < start:
+        case f.start >= end:
+            f.start += delta
+            f.end += delta
+        case f.start <= start && f.end >= end-1 && (f.start < start || count > 0):
+            f.end += delta
+        default:
+            continue
+        }
+        if f.start >= 0 && f.end > f.start {
+            kept = append(kept, f)
+        }
+    }
+    t.folds = kept
+}
+
+// syncFolds applies the buffer's edits since the last call to the folds
+// and drops any fold that runs past the end of the text.
+func (t *Tab) syncFolds() {
+    for _, ed := range t.buffer.takeLineEdits() {
+        t.adjustFolds(ed.start, ed.end, ed.count)
+    }
+    kept := t.folds[:0]
+    for _, f := range t.folds {
+        if f.end < t.buffer.LineCount() {
+            kept = append(kept, f)
+        }
+    }
+    t.folds = kept
+}
+
+// syncFolds moves the folds of every tab along with the edits to its
+// buffer, wherever they were made: typing, undo, formatting, a rename
+// from the language server or a project replace.
+func (e *Editor) syncFolds() {
+    for _, tab := range e.tabManager.tabs {
+        if tab.buffer != nil {
+            tab.syncFolds()
+        }
+    }
+}
+
+// foldRegions returns every foldable region of the buffer, sorted by
+// start line with enclosing regions first. Marker folds ({{{ ... }}})
+// work everywhere; languages with "fold": "brace" fold on brackets, all
+// others on indentation.
+func (e *Editor) foldRegions(tab *Tab) []foldRange {
+    b := tab.buffer
+    ends := make(map[int]int)
+    add := func(start, end int) {
+        if end > start && end > ends[start] {
+            ends[start] = end
+        }
+    }
+
+    var markers []int
+    for row := 0; row < b.LineCount(); row++ {
+        line := b.GetLine(row)
+        if strings.Contains(line, foldMarkerOpen) {
+            markers = append(markers, row)
+        }
+        if strings.Contains(line, foldMarkerClose) && len(markers) > 0 {
+            add(markers[len(markers)-1], row)
+            markers = markers[:len(markers)-1]
+        }
+    }
+
+    lang := e.languageFor(tab)
+    if lang.Fold == "brace" {
+        var open []bracketPos
+        for _, br := range e.brackets(tab) {
+            if _, ok := bracketPairs[br.ch]; ok {
+                open = append(open, br)
+                continue
+            }
+            if len(open) == 0 {
+                continue
+            }
+            opener := open[len(open)-1]
+            open = open[:len(open)-1]
+            end := br.row
+            // Keep lines like "} else {" visible below the folded block.
+            line := b.GetLine(end)
+            if !isBlank(line[:br.col]) || strings.Trim(line[br.col+1:], " \t)]};,") != "" {
+                end--
+            }
+            add(opener.row, end)
+        }
+    } else {
+        tw := e.tabWidth(tab)
+        indent := func(row int) int {
+            line := b.GetLine(row)
+            return displayCol(line, len(leadingWhitespace(line)), tw)
+        }
+        for row := 0; row < b.LineCount(); row++ {
+            if isBlank(b.GetLine(row)) {
+                continue
+            }
+            width, end := indent(row), row
+            for next := row + 1; next < b.LineCount(); next++ {
+                if isBlank(b.GetLine(next)) {
+                    continue
+                }
+                if indent(next) <= width {
+                    break
+                }
+                end = next
+            }
+            add(row, end)
+        }
+    }
+
+    regions := make([]foldRange, 0, len(ends))
+    for start, end := range ends {
+        regions = append(regions, foldRange{start, end})
+    }
+    sort.Slice(regions, func(i, j int) bool {
+        if regions[i].start != regions[j].start {
+            return regions[i].start < regions[j].start
+        }
+        return regions[i].end > regions[j].end
+    })
+    return regions
+}
+
+func (t *Tab) isClosed(f foldRange) bool {
+    for _, c := range t.folds {
+        if c == f {
+            return true
+        }
+    }
+    return false
+}
+
+// fold closes the innermost open region around the cursor.
+func (e *Editor) fold(tab *Tab) {
+    tab.syncFolds()
+    row := tab.cursor.Row
+    var best *foldRange
+    for _, r := range e.foldRegions(tab) {
+        r := r
+        if r.start <= row && row <= r.end && !tab.isClosed(r) {
+            best = &r
+        }
+    }
+    if best == nil {
+        e.setStatusMsg("Nothing to fold here")
+        return
+    }
+
+    tab.folds = append(tab.folds, *best)
+    tab.clearSelection()
+    tab.cursor.Row = best.start
+    e.ensureCursorValid(tab)
+    e.setStatusMsg(fmt.Sprintf("Folded lines %d-%d", best.start+1, best.end+1))
+}
+
+// unfold opens the folds at the cursor line.
+func (e *Editor) unfold(tab *Tab) {
+    tab.syncFolds()
+    row := tab.cursor.Row
+    kept := tab.folds[:0]
+    opened := 0
+    for _, f := range tab.folds {
+        if f.start <= row && row <= f.end {
+            opened++
+            continue
+        }
+        kept = append(kept, f)
+    }
+    tab.folds = kept
+    if opened == 0 {
+        e.setStatusMsg("No fold here")
+        return
+    }
+    e.setStatusMsg(fmt.Sprintf("Unfolded %d fold(s)", opened))
+}
+
+func (e *Editor) toggleFold(tab *Tab) {
+    if tab.closedFoldAt(tab.cursor.Row) != nil {
+        e.unfold(tab)
+    } else {
+        e.fold(tab)
+    }
+}
+
+// foldToLevel closes every region nested level or more deep, so level 0
+// folds everything and level 1 shows the top-level regions' headers
+// with their contents folded.
+func (e *Editor) foldToLevel(tab *Tab, level int) {
+    tab.syncFolds()
+    tab.folds = tab.folds[:0]
+    var enclosing []foldRange
+    for _, r := range e.foldRegions(tab) {
+        for len(enclosing) > 0 && enclosing[len(enclosing)-1].end < r.start {
+            enclosing = enclosing[:len(enclosing)-1]
+        }
+        if len(enclosing) >= level {
+            tab.folds = append(tab.folds, r)
+        }
+        enclosing = append(enclosing, r)
+    }
+    if f := tab.hidingFold(tab.cursor.Row); f != nil {
+        tab.cursor.Row = f.start
+        e.ensureCursorValid(tab)
+    }
+    tab.clearSelection()
+    e.setStatusMsg(fmt.Sprintf("Folded %d region(s)", len(tab.folds)))
+}
+
+func (e *Editor) unfoldAll(tab *Tab) {
+    tab.folds = nil
+    e.setStatusMsg("All folds opened")
+}
@@ -0,0 +1,141 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "go/format"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "time"
+    "unicode"
+)
+
+const formatTimeout = 10 * time.Second
+
+func hasFormatter(lang *Language) bool {
+    return len(lang.Formatter) > 0 || lang.Name == "go"
+}
+
+// formatText formats the text of a buffer in the given language. Go is
+// formatted in-process unless another formatter is configured; other
+// languages pipe the text through their formatter command, run in the
+// file's directory, where {file} stands for the absolute file name.
+func formatText(lang *Language, filename, text string) (string, error) {
+    if !hasFormatter(lang) {
+        return "", fmt.Errorf("no formatter configured for %s", lang.Name)
+    }
+    if len(lang.Formatter) == 0 {
+        out, err := format.Source([]byte(text + "\n"))
+        if err != nil {
+            return "", err
+        }
+        return strings.TrimSuffix(string(out), "\n"), nil
+    }
+
+    if filename != "" {
+        if abs, err := filepath.Abs(filename); err == nil {
+            filename = abs
+        }
+    }
+    args := make([]string, len(lang.Formatter))
+    for i, arg := range lang.Formatter {
+        args[i] = strings.ReplaceAll(arg, "{file}", filename)
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), formatTimeout)
+    defer cancel()
+
+    cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+    if filename != "" {
+        cmd.Dir = filepath.Dir(filename)
+    }
+    var stdout, stderr bytes.Buffer
+    cmd.Stdin = strings.NewReader(text + "\n")
+    cmd.Stdout = &stdout
+    cmd.Stderr = &stderr
+    if err := cmd.Run(); err != nil {
+        if errors.Is(err, exec.ErrNotFound) {
+            return "", fmt.Errorf("%s not found", args[0])
+        }
+        if ctx.Err() != nil {
+            return "", fmt.Errorf("%s timed out", args[0])
+        }
+        if msg := strings.TrimSpace(stderr.String()); msg != "" {
+            return "", fmt.Errorf("%s: %s", args[0], strings.SplitN(msg, "\n", 2)[0])
+        }
+        return "", fmt.Errorf("%s: %v", args[0], err)
+    }
+    return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+// formattedOffset maps a byte offset in old to the same place in new,
+// counting the non-space characters before it. Formatters mostly move
+// whitespace, so this keeps the cursor next to the same code.
+func formattedOffset(old, new string, offset int) int {
+    count := 0
+    for _, r := range old[:offset] {
+        if !unicode.IsSpace(r) {
+            count++
+        }
+    }
+    for i, r := range new {
+        if unicode.IsSpace(r) {
+            continue
+        }
+        if count == 0 {
+            return i
+        }
+        count--
+    }
+    return len(new)
+}
+
+// formatBuffer rewrites the buffer with its formatter's output as a
+// single undo step, keeping the cursor at the same code.
+func (e *Editor) formatBuffer(tab *Tab) error {
+    old := tab.buffer.GetText()
+    formatted, err := formatText(e.languageFor(tab), tab.buffer.filename, old)
+    if err != nil {
+        return err
+    }
+    if formatted == old {
+        return nil
+    }
+
+    offset := 0
+    for row := 0; row < tab.cursor.Row; row++ {
+        offset += len(tab.buffer.GetLine(row)) + 1
+    }
+    offset += clampCol(tab.buffer.GetLine(tab.cursor.Row), tab.cursor.Col)
+    offset = formattedOffset(old, formatted, offset)
+
+    tab.clearSelection()
+    tab.buffer.ReplaceLines(0, tab.buffer.LineCount(), strings.Split(formatted, "\n"))
+    before := formatted[:offset]
+    tab.cursor.Row = strings.Count(before, "\n")
+    tab.cursor.Col = len(before) - strings.LastIndexByte(before, '\n') - 1
+    e.ensureCursorValid(tab)
+    tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+    return nil
+}
+
+// formatDocument is the format command: it formats the active buffer
+// without saving it.
+func (e *Editor) formatDocument(tab *Tab) {
+    old := tab.buffer.GetText()
+    if err := e.formatBuffer(tab); err != nil {
+        e.setStatusMsg(fmt.Sprintf("Format failed: %v", err))
+        return
+    }
+    if tab.buffer.GetText() == old {
+        e.setStatusMsg("Already formatted")
+        return
+    }
+    e.setStatusMsg("Formatted")
+}
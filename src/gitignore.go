@@ -0,0 +1,167 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "bufio"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+)
+
+type ignoreRule struct {
+    pattern *regexp.Regexp
+    negate  bool
+    dirOnly bool
+}
+
+// ignoreMatcher applies the .gitignore files of a directory tree. Rules
+// are loaded per directory while walking it.
+type ignoreMatcher struct {
+    root  string
+    rules map[string][]ignoreRule
+}
+
+func newIgnoreMatcher(root string) *ignoreMatcher {
+    return &ignoreMatcher{root: root, rules: make(map[string][]ignoreRule)}
+}
+
+// load reads dir/.gitignore. It must be called for a directory before
+// any of its entries are checked.
+func (m *ignoreMatcher) load(dir string) {
+    file, err := os.Open(filepath.Join(dir, ".gitignore"))
+    if err != nil {
+        return
+    }
+    defer file.Close()
+
+    var rules []ignoreRule
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        line := trimIgnoreLine(scanner.Text())
+        if line == "" || line[0] == '#' {
+            continue
+        }
+        var rule ignoreRule
+        if line[0] == '!' {
+            rule.negate = true
+            line = line[1:]
+        }
+        if strings.HasSuffix(line, "/") {
+            rule.dirOnly = true
+            line = strings.TrimRight(line, "/")
+        }
+        re, err := ignoreGlob(line)
+        if err != nil {
+            continue
+        }
+        rule.pattern = re
+        rules = append(rules, rule)
+    }
+    if len(rules) > 0 {
+        m.rules[dir] = rules
+    }
+}
+
+// trimIgnoreLine removes trailing spaces that are not escaped with a
+// backslash.
+func trimIgnoreLine(line string) string {
+    for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+        line = line[:len(line)-1]
+    }
+    return line
+}
+
+// ignoreGlob turns a .gitignore pattern into a regular expression over
+// slash-separated paths relative to the .gitignore file. Unlike
+// EditorConfig globs, braces are literal and a backslash escapes the
+// next character. Patterns without a slash match in any subdirectory.
+func ignoreGlob(glob string) (*regexp.Regexp, error) {
+    if !strings.Contains(glob, "/") {
+        glob = "**/" + glob
+    }
+    glob = strings.TrimPrefix(glob, "/")
+
+    var sb strings.Builder
+    sb.WriteString("^")
+    for i := 0; i < len(glob); i++ {
+        c := glob[i]
+        switch c {
+        case '*':
+            atStart := i == 0 || glob[i-1] == '/'
+            switch {
+            case atStart && strings.HasPrefix(glob[i:], "**/"):
+                sb.WriteString("(?:.*/)?")
+                i += 2
+            case atStart && glob[i:] == "**":
+                sb.WriteString(".*")
+                i++
+            default:
+                sb.WriteString("[^/]*")
+                for i+1 < len(glob) && glob[i+1] == '*' {
+                    i++
+                }
+            }
+        case '?':
+            sb.WriteString("[^/]")
+        case '[':
+            end := strings.IndexByte(glob[i+1:], ']')
+            if end < 0 {
+                sb.WriteString(`\[`)
+                continue
+            }
+            class := glob[i+1 : i+1+end]
+            if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+                class = "^" + class[1:]
+            }
+            sb.WriteString("[" + class + "]")
+            i += end + 1
+        case '\\':
+            if i+1 < len(glob) {
+                i++
+                sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+            }
+        default:
+            sb.WriteString(regexp.QuoteMeta(string(c)))
+        }
+    }
+    sb.WriteString("$")
+    return regexp.Compile(sb.String())
+}
+
+// ignored reports whether path is excluded. Rules of deeper directories
+// come later, and the last matching rule wins.
+func (m *ignoreMatcher) ignored(path string, isDir bool) bool {
+    if isDir && filepath.Base(path) == ".git" {
+        return true
+    }
+
+    var dirs []string
+    for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+        dirs = append(dirs, dir)
+        if dir == m.root || filepath.Dir(dir) == dir {
+            break
+        }
+    }
+
+    ignored := false
+    for i := len(dirs) - 1; i >= 0; i-- {
+        rel, err := filepath.Rel(dirs[i], path)
+        if err != nil {
+            continue
+        }
+        rel = filepath.ToSlash(rel)
+        for _, rule := range m.rules[dirs[i]] {
+            if rule.dirOnly && !isDir {
+                continue
+            }
+            if rule.pattern.MatchString(rel) {
+                ignored = !rule.negate
+            }
+        }
+    }
+    return ignored
+}
@@ -0,0 +1,427 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "bytes"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "regexp"
+    "runtime"
+    "strings"
+    "sync"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const (
+    maxGrepResults  = 10000
+    maxGrepFileSize = 16 * 1024 * 1024
+    maxPreviewLen   = 200
+)
+
+type grepResult struct {
+    path    string
+    row     int
+    col     int
+    length  int
+    preview string
+    offset  int
+}
+
+// grepSearch is a project search running in the background. Results
+// arrive file by file, so the matches of one file stay together.
+type grepSearch struct {
+    query    string
+    re       *regexp.Regexp
+    regex    bool
+    mu       sync.Mutex
+    results  []grepResult
+    files    int
+    done     bool
+    err      error
+    cancel   chan struct{}
+    selected int
+    top      int
+    note     string
+}
+
+func (g *grepSearch) snapshot() ([]grepResult, int, bool) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return g.results, g.files, g.done
+}
+
+func (g *grepSearch) stop() {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if !g.done {
+        g.done = true
+        close(g.cancel)
+    }
+}
+
+func (g *grepSearch) cancelled() bool {
+    select {
+    case <-g.cancel:
+        return true
+    default:
+        return false
+    }
+}
+
+// isBinary treats data with a NUL byte near the start as binary.
+func isBinary(data []byte) bool {
+    if len(data) > 8000 {
+        data = data[:8000]
+    }
+    return bytes.IndexByte(data, 0) >= 0
+}
+
+func grepFile(path string, re *regexp.Regexp) []grepResult {
+    info, err := os.Stat(path)
+    if err != nil || info.Size() > maxGrepFileSize {
+        return nil
+    }
+    data, err := os.ReadFile(path)
+    if err != nil || isBinary(data) {
+        return nil
+    }
+
+    var results []grepResult
+    for row, line := range strings.Split(string(data), "\n") {
+        line = strings.TrimSuffix(line, "\r")
+        for _, m := range re.FindAllStringIndex(line, -1) {
+            if m[1] == m[0] {
+                continue
+            }
+            preview := strings.TrimLeft(line, " \t")
+            offset := len(line) - len(preview)
+            if len(preview) > maxPreviewLen {
+                preview = preview[:maxPreviewLen]
+            }
+            results = append(results, grepResult{
+                path: path, row: row, col: m[0], length: m[1] - m[0],
+                preview: preview, offset: offset,
+            })
+        }
+    }
+    return results
+}
+
+// walkProject sends every file under root that is not excluded by a
+// .gitignore file, until cancel is closed.
+func walkProject(root string, files chan<- string, cancel <-chan struct{}) error {
+    ignore := newIgnoreMatcher(root)
+    return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+        if err != nil {
+            if path == root {
+                return err
+            }
+            return nil
+        }
+        if path != root && ignore.ignored(path, d.IsDir()) {
+            if d.IsDir() {
+                return filepath.SkipDir
+            }
+            return nil
+        }
+        if d.IsDir() {
+            ignore.load(path)
+            return nil
+        }
+        if !d.Type().IsRegular() {
+            return nil
+        }
+        select {
+        case files <- path:
+            return nil
+        case <-cancel:
+            return filepath.SkipAll
+        }
+    })
+}
+
+// startGrep searches the working directory for query with the current
+// search options and shows the results list while they come in.
+func (e *Editor) startGrep(query string) {
+    opts := e.searchOpts
+    opts.Multiline = false
+    re, err := compileSearch(query, opts)
+    if err != nil {
+        e.setStatusMsg(err.Error())
+        return
+    }
+    root, err := os.Getwd()
+    if err != nil {
+        e.setStatusMsg(fmt.Sprintf("Grep failed: %v", err))
+        return
+    }
+
+    if e.grep != nil {
+        e.grep.stop()
+    }
+    g := &grepSearch{query: query, re: re, regex: opts.Regex, cancel: make(chan struct{})}
+    e.grep = g
+    e.mode = ModeGrepResults
+
+    files := make(chan string)
+    var wg sync.WaitGroup
+    for i := 0; i < runtime.NumCPU(); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for path := range files {
+                if g.cancelled() {
+                    continue
+                }
+                results := grepFile(path, re)
+                rel, err := filepath.Rel(root, path)
+                if err == nil {
+                    for i := range results {
+                        results[i].path = rel
+                    }
+                }
+
+                g.mu.Lock()
+                g.files++
+                if !g.done {
+                    g.results = append(g.results, results...)
+                    if len(g.results) >= maxGrepResults {
+                        g.done = true
+                        close(g.cancel)
+                    }
+                }
+                g.mu.Unlock()
+                if len(results) > 0 {
+                    e.redraw()
+                }
+            }
+        }()
+    }
+
+    go func() {
+        err := walkProject(root, files, g.cancel)
+        close(files)
+        wg.Wait()
+        g.mu.Lock()
+        if !g.done {
+            g.done = true
+            close(g.cancel)
+        }
+        g.err = err
+        g.mu.Unlock()
+        e.redraw()
+    }()
+}
+
+// redraw wakes up the event loop so results from background work show
+// up without waiting for a key press.
+func (e *Editor) redraw() {
+    if e.screen != nil {
+        e.screen.PostEvent(tcell.NewEventInterrupt(nil))
+    }
+}
+
+func (e *Editor) grepPrompt() string {
+    return "Grep" + e.searchOpts.String() + ": " + e.inputBuffer
+}
+
+func (e *Editor) startGrepPrompt() {
+    e.mode = ModeGrep
+    e.inputBuffer = ""
+    e.historyIndex = len(e.searchHistory)
+    e.setStatusMsg(e.grepPrompt())
+}
+
+func (e *Editor) handleGrepMode(ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.setStatusMsg("Grep cancelled")
+    case tcell.KeyEnter:
+        if e.inputBuffer == "" {
+            if e.grep != nil {
+                e.mode = ModeGrepResults
+                return true
+            }
+            e.mode = ModeNormal
+            e.setStatusMsg("No search query entered")
+            return true
+        }
+        e.addSearchHistory(e.inputBuffer)
+        e.startGrep(e.inputBuffer)
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg(e.grepPrompt())
+    case tcell.KeyUp:
+        e.browseHistory(-1)
+        e.setStatusMsg(e.grepPrompt())
+    case tcell.KeyDown:
+        e.browseHistory(1)
+        e.setStatusMsg(e.grepPrompt())
+    case tcell.KeyRune:
+        if ev.Modifiers()&tcell.ModAlt != 0 {
+            e.searchOpts.toggle(ev.Rune())
+        } else {
+            e.inputBuffer += string(ev.Rune())
+        }
+        e.setStatusMsg(e.grepPrompt())
+    }
+    return true
+}
+
+func (e *Editor) handleGrepResultsMode(ev *tcell.EventKey) bool {
+    g := e.grep
+    if g == nil {
+        e.mode = ModeNormal
+        return true
+    }
+    results, _, _ := g.snapshot()
+    g.note = ""
+
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        g.stop()
+        e.mode = ModeNormal
+        e.setStatusMsg("Grep results closed. Alt+G then Enter reopens them")
+    case tcell.KeyUp:
+        g.selected--
+    case tcell.KeyDown:
+        g.selected++
+    case tcell.KeyPgUp:
+        g.selected -= e.height
+    case tcell.KeyPgDn:
+        g.selected += e.height
+    case tcell.KeyHome:
+        g.selected = 0
+    case tcell.KeyEnd:
+        g.selected = len(results) - 1
+    case tcell.KeyEnter:
+        if g.selected >= 0 && g.selected < len(results) {
+            e.openResult(results[g.selected])
+        }
+    case tcell.KeyRune:
+        if ev.Rune() == 'r' {
+            e.startProjectReplace()
+        }
+    }
+    if g.selected >= len(results) {
+        g.selected = len(results) - 1
+    }
+    if g.selected < 0 {
+        g.selected = 0
+    }
+    return true
+}
+
+// openResult shows a result in its tab, opening the file if needed, and
+// selects the match.
+func (e *Editor) openResult(r grepResult) {
+    if err := e.openFile(r.path); err != nil {
+        e.setStatusMsg(fmt.Sprintf("Failed to open %s: %v", r.path, err))
+        return
+    }
+    tab := e.tabManager.GetActiveTab()
+    e.mode = ModeNormal
+    selectMatch(tab, searchMatch{r.row, r.col, r.row, r.col + r.length})
+    e.ensureCursorValid(tab)
+    e.setStatusMsg(fmt.Sprintf("%s:%d:%d", r.path, r.row+1, r.col+1))
+}
+
+// renderGrepResults draws the results list over the text area, with a
+// header line for each file.
+func (e *Editor) renderGrepResults() {
+    g := e.grep
+    results, files, done := g.snapshot()
+
+    type listRow struct {
+        header string
+        index  int
+    }
+    var rows []listRow
+    selRow := 0
+    for i, r := range results {
+        if i == 0 || r.path != results[i-1].path {
+            count := 0
+            for j := i; j < len(results) && results[j].path == r.path; j++ {
+                count++
+            }
+            rows = append(rows, listRow{header: fmt.Sprintf("%s (%d)", r.path, count), index: -1})
+        }
+        if i == g.selected {
+            selRow = len(rows)
+        }
+        rows = append(rows, listRow{index: i})
+    }
+
+    if selRow < g.top+1 {
+        g.top = selRow - 1
+    }
+    if selRow >= g.top+e.height {
+        g.top = selRow - e.height + 1
+    }
+    if g.top < 0 {
+        g.top = 0
+    }
+
+    headerStyle := tcell.StyleDefault.Foreground(tcell.ColorBlue).Bold(true)
+    for y := 0; y < e.height && g.top+y < len(rows); y++ {
+        row := rows[g.top+y]
+        screenY := y + 1
+        if row.index < 0 {
+            e.drawString(0, screenY, row.header, headerStyle)
+            continue
+        }
+
+        r := results[row.index]
+        style := tcell.StyleDefault
+        if row.index == g.selected {
+            style = style.Reverse(true)
+        }
+        prefix := fmt.Sprintf("%6d:%-4d ", r.row+1, r.col+1)
+        x := 0
+        for _, c := range prefix {
+            e.screen.SetContent(x, screenY, c, nil, style.Foreground(tcell.ColorGray))
+            x++
+        }
+        for i, c := range r.preview {
+            if x >= e.width {
+                break
+            }
+            cs := style
+            if col := r.offset + i; col >= r.col && col < r.col+r.length {
+                cs = cs.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+            }
+            if c == '\t' {
+                c = ' '
+            }
+            e.screen.SetContent(x, screenY, c, nil, cs)
+            x++
+        }
+    }
+
+    if e.mode != ModeGrepResults {
+        return
+    }
+    status := fmt.Sprintf("Grep '%s': %d matches, %d files searched", g.query, len(results), files)
+    if g.re == nil {
+        status = fmt.Sprintf("%s: %d locations", g.query, len(results))
+    } else if !done {
+        status += " (searching, Esc to cancel)"
+    } else if len(results) >= maxGrepResults {
+        status += fmt.Sprintf(" (stopped at %d)", maxGrepResults)
+    } else if g.err != nil {
+        status += fmt.Sprintf(" (%v)", g.err)
+    }
+    if g.note != "" {
+        status += " - " + g.note
+    }
+    e.setStatusMsg(status + " | Enter: open, r: replace, Esc: close")
+}
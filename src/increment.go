@@ -0,0 +1,278 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+)
+
+var (
+    dateTimeRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2})?)?|\b\d{2}:\d{2}(?::\d{2})?\b`)
+    numberRe   = regexp.MustCompile(`-?(?:0[xX][0-9a-fA-F]+|0[bB][01]+|0[oO][0-7]+|\d+)`)
+    booleanRe  = regexp.MustCompile(`(?i)\b(?:true|false|yes|no|on|off)\b`)
+)
+
+var booleanPairs = map[string]string{
+    "true": "false", "false": "true",
+    "yes": "no", "no": "yes",
+    "on": "off", "off": "on",
+}
+
+var dateTimeLayouts = []string{
+    "2006-01-02T15:04:05", "2006-01-02 15:04:05",
+    "2006-01-02T15:04", "2006-01-02 15:04",
+    "2006-01-02", "15:04:05", "15:04",
+}
+
+// stepToken is a number, date, time or boolean found in a line.
+type stepToken struct {
+    start int
+    end   int
+    kind  string
+}
+
+// findStepToken returns the first token under or after col.
+func findStepToken(line string, col int) (stepToken, bool) {
+    var tokens []stepToken
+    dates := dateTimeRe.FindAllStringIndex(line, -1)
+    for _, m := range dates {
+        tokens = append(tokens, stepToken{m[0], m[1], "date"})
+    }
+    for _, m := range numberRe.FindAllStringIndex(line, -1) {
+        start := m[0]
+        if line[start] == '-' && start > 0 && isWordRune(rune(line[start-1])) {
+            start++
+        }
+        inDate := false
+        for _, d := range dates {
+            if start < d[1] && m[1] > d[0] {
+                inDate = true
+            }
+        }
+        if !inDate {
+            tokens = append(tokens, stepToken{start, m[1], "number"})
+        }
+    }
+    for _, m := range booleanRe.FindAllStringIndex(line, -1) {
+        // yes/no and on/off are common words, so only the one under
+        // the cursor is toggled; true/false may also follow it.
+        word := strings.ToLower(line[m[0]:m[1]])
+        if word == "true" || word == "false" || m[0] <= col {
+            tokens = append(tokens, stepToken{m[0], m[1], "boolean"})
+        }
+    }
+
+    sort.Slice(tokens, func(i, j int) bool { return tokens[i].start < tokens[j].start })
+    for _, t := range tokens {
+        if t.end > col {
+            return t, true
+        }
+    }
+    return stepToken{}, false
+}
+
+// stepNumber adds delta to a decimal, hex (0x), binary (0b) or octal
+// (0o) literal, keeping its width, zero padding and letter case.
+func stepNumber(text string, delta int64) (string, error) {
+    if len(text) > 2 && text[0] == '0' && strings.IndexByte("xXbBoO", text[1]) >= 0 {
+        base := map[byte]int{'x': 16, 'b': 2, 'o': 8}[text[1]|0x20]
+        digits := text[2:]
+        v, err := strconv.ParseUint(digits, base, 64)
+        if err != nil {
+            return "", err
+        }
+        if delta < 0 && uint64(-delta) > v {
+            return "", fmt.Errorf("%s cannot go below zero", text)
+        }
+        out := strconv.FormatUint(v+uint64(delta), base)
+        if strings.ContainsAny(digits, "ABCDEF") {
+            out = strings.ToUpper(out)
+        }
+        if len(out) < len(digits) {
+            out = strings.Repeat("0", len(digits)-len(out)) + out
+        }
+        return text[:2] + out, nil
+    }
+
+    v, err := strconv.ParseInt(text, 10, 64)
+    if err != nil {
+        return "", err
+    }
+    digits := strings.TrimPrefix(text, "-")
+    n := v + delta
+    out := strconv.FormatInt(n, 10)
+    if len(digits) > 1 && digits[0] == '0' {
+        abs := strings.TrimPrefix(out, "-")
+        if len(abs) < len(digits) {
+            abs = strings.Repeat("0", len(digits)-len(abs)) + abs
+        }
+        if n < 0 {
+            abs = "-" + abs
+        }
+        out = abs
+    }
+    return out, nil
+}
+
+// dateUnitAt names the date or time component at offset in text, which
+// is formatted with layout. A negative offset, for a cursor before the
+// text, picks the day, or the minute for a plain time.
+func dateUnitAt(layout string, offset int) string {
+    if offset < 0 {
+        if strings.Contains(layout, "02") {
+            return "day"
+        }
+        return "minute"
+    }
+    units := []struct {
+        field, unit string
+    }{
+        {"2006", "year"}, {"01", "month"}, {"02", "day"},
+        {"15", "hour"}, {"04", "minute"}, {"05", "second"},
+    }
+    unit := ""
+    for _, u := range units {
+        i := strings.Index(layout, u.field)
+        if i < 0 {
+            continue
+        }
+        if unit == "" || offset >= i {
+            unit = u.unit
+        }
+    }
+    return unit
+}
+
+func addMonths(t time.Time, n int) time.Time {
+    y, m, d := t.Date()
+    first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+    if last := first.AddDate(0, 1, -1).Day(); d > last {
+        d = last
+    }
+    return first.AddDate(0, 0, d-1)
+}
+
+// stepDateTime moves an ISO date or time by delta units. Without an
+// explicit unit the component under the cursor is used.
+func stepDateTime(text string, offset, delta int, unit string) (string, error) {
+    for _, layout := range dateTimeLayouts {
+        if len(layout) != len(text) {
+            continue
+        }
+        t, err := time.Parse(layout, text)
+        if err != nil {
+            continue
+        }
+        if unit == "" {
+            unit = dateUnitAt(layout, offset)
+        }
+        switch unit {
+        case "year":
+            t = addMonths(t, 12*delta)
+        case "month":
+            t = addMonths(t, delta)
+        case "day":
+            t = t.AddDate(0, 0, delta)
+        case "hour":
+            t = t.Add(time.Duration(delta) * time.Hour)
+        case "minute":
+            t = t.Add(time.Duration(delta) * time.Minute)
+        case "second":
+            t = t.Add(time.Duration(delta) * time.Second)
+        default:
+            return "", fmt.Errorf("unknown unit '%s'", unit)
+        }
+        return t.Format(layout), nil
+    }
+    return "", fmt.Errorf("invalid date '%s'", text)
+}
+
+// cycleBoolean swaps a boolean word for its opposite, keeping the case.
+func cycleBoolean(text string) string {
+    out := booleanPairs[strings.ToLower(text)]
+    switch {
+    case text == strings.ToUpper(text):
+        return strings.ToUpper(out)
+    case text[:1] == strings.ToUpper(text[:1]):
+        return strings.ToUpper(out[:1]) + out[1:]
+    }
+    return out
+}
+
+// stepAt changes the token under or after col in row by delta and
+// returns the token's new start and text.
+func (e *Editor) stepAt(tab *Tab, row, col int, delta int, unit string) (int, string, error) {
+    line := tab.buffer.GetLine(row)
+    tok, ok := findStepToken(line, col)
+    if !ok {
+        return 0, "", fmt.Errorf("no number, date or boolean at cursor")
+    }
+
+    text := line[tok.start:tok.end]
+    var out string
+    var err error
+    switch tok.kind {
+    case "date":
+        out, err = stepDateTime(text, col-tok.start, delta, unit)
+    case "number":
+        out, err = stepNumber(text, int64(delta))
+    case "boolean":
+        out = text
+        if delta%2 != 0 {
+            out = cycleBoolean(text)
+        }
+    }
+    if err != nil {
+        return 0, "", err
+    }
+
+    tab.buffer.SetLine(row, line[:tok.start]+out+line[tok.end:])
+    return tok.start, out, nil
+}
+
+// increment adds count to the number, date or boolean under or after the
+// cursor. On a selection every line is stepped from the selection's
+// left column, with increasing amounts so the lines form a sequence.
+func (e *Editor) increment(tab *Tab, count int, unit string) {
+    if startRow, startCol, endRow, endCol, ok := tab.selectionRange(); ok {
+        if endCol < startCol {
+            startCol = endCol
+        }
+        if endCol == 0 && endRow > startRow {
+            endRow--
+        }
+        changed := 0
+        for row := startRow; row <= endRow; row++ {
+            if _, _, err := e.stepAt(tab, row, startCol, count*(changed+1), unit); err == nil {
+                changed++
+            }
+        }
+        if changed == 0 {
+            e.actionFailed = true
+            e.setStatusMsg("No number, date or boolean in selection")
+            return
+        }
+        tab.clearSelection()
+        tab.cursor.Row, tab.cursor.Col = startRow, startCol
+        e.commitLines(tab, fmt.Sprintf("Stepped %d line(s)", changed))
+        return
+    }
+
+    start, out, err := e.stepAt(tab, tab.cursor.Row, tab.cursor.Col, count, unit)
+    if err != nil {
+        e.actionFailed = true
+        e.setStatusMsg(err.Error())
+        return
+    }
+    _, size := utf8.DecodeLastRuneInString(out)
+    tab.cursor.Col = start + len(out) - size
+    e.commitLines(tab, fmt.Sprintf("Changed to %s", out))
+}
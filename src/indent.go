@@ -0,0 +1,158 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "strings"
+    "unicode"
+)
+
+func leadingWhitespace(line string) string {
+    for i, r := range line {
+        if r != ' ' && r != '\t' {
+            return line[:i]
+        }
+    }
+    return line
+}
+
+func isBlank(s string) bool {
+    return strings.TrimLeft(s, " \t") == ""
+}
+
+func indentWidth(unit string) int {
+    if unit == "" || unit == "\t" {
+        return 4
+    }
+    return len(unit)
+}
+
+// endsWithOpener reports whether the code before the cursor ends with
+// one of the language's indent openers. Word openers such as "then" or
+// "do" must stand on their own.
+func endsWithOpener(lang *Language, text string) bool {
+    text = strings.TrimRight(text, " \t")
+    for _, op := range lang.IndentOpeners {
+        if op == "" || !strings.HasSuffix(text, op) {
+            continue
+        }
+        if isWordRune(rune(op[0])) {
+            rest := text[:len(text)-len(op)]
+            if rest != "" && isWordRune(rune(rest[len(rest)-1])) {
+                continue
+            }
+        }
+        return true
+    }
+    return false
+}
+
+func startsWithCloser(lang *Language, text string) bool {
+    text = strings.TrimLeft(text, " \t")
+    for _, cl := range lang.IndentClosers {
+        if cl != "" && strings.HasPrefix(text, cl) {
+            return true
+        }
+    }
+    return false
+}
+
+func isWordRune(r rune) bool {
+    return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// dedent removes one indentation level from the start of indent.
+func dedent(indent, unit string) string {
+    if indent == "" {
+        return indent
+    }
+    if strings.HasSuffix(indent, "\t") {
+        return indent[:len(indent)-1]
+    }
+    n := len(indent) - len(strings.TrimRight(indent, " "))
+    remove := len(indent) % indentWidth(unit)
+    if remove == 0 {
+        remove = indentWidth(unit)
+    }
+    if remove > n {
+        remove = n
+    }
+    return indent[:len(indent)-remove]
+}
+
+// smartNewline splits the line at the cursor, carries the indentation
+// over and adds or removes a level based on the surrounding brackets.
+func (e *Editor) smartNewline(tab *Tab) {
+    lang := e.languageFor(tab)
+    row := tab.cursor.Row
+    line := tab.buffer.GetLine(row)
+    col := tab.cursor.Col
+    if col > len(line) {
+        col = len(line)
+    }
+
+    before := line[:col]
+    after := strings.TrimLeft(line[col:], " \t")
+
+    indent := leadingWhitespace(line)
+    if len(indent) > len(before) {
+        indent = before
+    }
+
+    inner := indent
+    if endsWithOpener(lang, before) {
+        inner = indent + lang.IndentUnit
+    }
+
+    if inner != indent && startsWithCloser(lang, after) {
+        tab.buffer.SetLine(row, before)
+        tab.buffer.InsertText(row, len(before), "\n"+inner+"\n"+indent+after)
+    } else {
+        if startsWithCloser(lang, after) {
+            inner = dedent(inner, lang.IndentUnit)
+        }
+        tab.buffer.SetLine(row, before)
+        tab.buffer.InsertText(row, len(before), "\n"+inner+after)
+    }
+
+    tab.cursor.Row = row + 1
+    tab.cursor.Col = len(inner)
+}
+
+// electricCloser dedents the current line when a closing bracket is
+// typed as its first non-blank character.
+func (e *Editor) electricCloser(tab *Tab, ch rune) {
+    lang := e.languageFor(tab)
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    col := tab.cursor.Col
+    if col == 0 || col > len(line) || !isBlank(line[:col]) {
+        return
+    }
+
+    for _, cl := range lang.IndentClosers {
+        if cl == string(ch) {
+            indent := dedent(line[:col], lang.IndentUnit)
+            tab.buffer.SetLine(tab.cursor.Row, indent+line[col:])
+            tab.cursor.Col = len(indent)
+            return
+        }
+    }
+}
+
+// smartBackspace removes one indentation level when the cursor sits in
+// the leading whitespace of a line. It reports whether it did anything.
+func (e *Editor) smartBackspace(tab *Tab) bool {
+    lang := e.languageFor(tab)
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    col := tab.cursor.Col
+    if col == 0 || col > len(line) || !isBlank(line[:col]) {
+        return false
+    }
+
+    indent := dedent(line[:col], lang.IndentUnit)
+    tab.buffer.SetLine(tab.cursor.Row, indent+line[col:])
+    tab.cursor.Col = len(indent)
+    return true
+}
@@ -0,0 +1,327 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "path/filepath"
+    "strings"
+)
+
+const defaultIndentUnit = "    "
+
+// Language holds the per-filetype editing settings. Every field can be
+// overridden from the "languages" section of the config file.
+type Language struct {
+    Name          string   `json:"-"`
+    Extensions    []string `json:"extensions,omitempty"`
+    Filenames     []string `json:"filenames,omitempty"`
+    IndentUnit    string   `json:"indent_unit,omitempty"`
+    IndentOpeners []string `json:"indent_openers,omitempty"`
+    IndentClosers []string `json:"indent_closers,omitempty"`
+    LineComment   string   `json:"line_comment,omitempty"`
+    BlockComment  []string `json:"block_comment,omitempty"`
+    Quotes        string   `json:"quotes,omitempty"`
+    WordChars     string   `json:"word_chars,omitempty"`
+    Fold          string   `json:"fold,omitempty"`
+    Server        []string `json:"server,omitempty"`
+    Formatter     []string `json:"formatter,omitempty"`
+}
+
+var plainText = &Language{
+    Name:       "text",
+    IndentUnit: defaultIndentUnit,
+    WordChars:  "_",
+}
+
+func builtinLanguages() []*Language {
+    brackets := []string{"{", "(", "["}
+    closers := []string{"}", ")", "]"}
+    cBlock := []string{"/*", "*/"}
+    xmlBlock := []string{"<!--", "-->"}
+
+    return []*Language{
+        {
+            Name:          "go",
+            Extensions:    []string{".go"},
+            IndentUnit:    "\t",
+            IndentOpeners: brackets,
+            IndentClosers: closers,
+            LineComment:   "//",
+            BlockComment:  cBlock,
+            Quotes:        "\"'`",
+            WordChars:     "_",
+            Fold:          "brace",
+            Server:        []string{"gopls"},
+        },
+        {
+            Name:          "c",
+            Extensions:    []string{".c", ".h", ".cc", ".cpp", ".hpp", ".cxx", ".java", ".cs", ".swift", ".kt"},
+            IndentUnit:    defaultIndentUnit,
+            IndentOpeners: brackets,
+            IndentClosers: closers,
+            LineComment:   "//",
+            BlockComment:  cBlock,
+            Quotes:        "\"'",
+            WordChars:     "_",
+            Fold:          "brace",
+        },
+        {
+            // No ' quotes: lifetimes like 'a are not character literals.
+            Name:          "rust",
+            Extensions:    []string{".rs"},
+            IndentUnit:    defaultIndentUnit,
+            IndentOpeners: brackets,
+            IndentClosers: closers,
+            LineComment:   "//",
+            BlockComment:  cBlock,
+            Quotes:        "\"",
+            WordChars:     "_",
+            Fold:          "brace",
+        },
+        {
+            Name:          "javascript",
+            Extensions:    []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"},
+            IndentUnit:    "  ",
+            IndentOpeners: brackets,
+            IndentClosers: closers,
+            LineComment:   "//",
+            BlockComment:  cBlock,
+            Quotes:        "\"'`",
+            WordChars:     "_",
+            Fold:          "brace",
+            Formatter:     []string{"prettier", "--stdin-filepath", "{file}"},
+        },
+        {
+            Name:          "css",
+            Extensions:    []string{".css", ".scss", ".less"},
+            IndentUnit:    "  ",
+            IndentOpeners: brackets,
+            IndentClosers: closers,
+            BlockComment:  cBlock,
+            Quotes:        "\"'",
+            WordChars:     "_-",
+            Fold:          "brace",
+            Formatter:     []string{"prettier", "--stdin-filepath", "{file}"},
+        },
+        {
+            Name:          "json",
+            Extensions:    []string{".json"},
+            IndentUnit:    "  ",
+            IndentOpeners: brackets,
+            IndentClosers: closers,
+            Quotes:        "\"",
+            WordChars:     "_",
+            Fold:          "brace",
+            Formatter:     []string{"prettier", "--stdin-filepath", "{file}"},
+        },
+        {
+            Name:          "python",
+            Extensions:    []string{".py", ".pyw"},
+            IndentUnit:    defaultIndentUnit,
+            IndentOpeners: []string{":", "{", "(", "["},
+            IndentClosers: closers,
+            LineComment:   "#",
+            Quotes:        "\"'",
+            WordChars:     "_",
+            Formatter:     []string{"black", "--quiet", "-"},
+        },
+        {
+            Name:          "shell",
+            Extensions:    []string{".sh", ".bash", ".zsh"},
+            Filenames:     []string{".bashrc", ".zshrc", ".profile"},
+            IndentUnit:    defaultIndentUnit,
+            IndentOpeners: []string{"{", "(", "then", "do", "else", "in"},
+            IndentClosers: []string{"}", ")"},
+            LineComment:   "#",
+            Quotes:        "\"'`",
+            WordChars:     "_-",
+            Formatter:     []string{"shfmt", "-filename", "{file}"},
+        },
+        {
+            Name:          "yaml",
+            Extensions:    []string{".yaml", ".yml"},
+            IndentUnit:    "  ",
+            IndentOpeners: []string{":", "|", ">"},
+            LineComment:   "#",
+            Quotes:        "\"'",
+            WordChars:     "_-",
+        },
+        {
+            Name:          "toml",
+            Extensions:    []string{".toml", ".ini", ".conf", ".cfg"},
+            Filenames:     []string{".editorconfig", ".gitconfig"},
+            IndentUnit:    defaultIndentUnit,
+            IndentOpeners: []string{"[", "{"},
+            IndentClosers: []string{"]", "}"},
+            LineComment:   "#",
+            Quotes:        "\"'",
+            WordChars:     "_-",
+        },
+        {
+            Name:          "ruby",
+            Extensions:    []string{".rb"},
+            IndentUnit:    "  ",
+            IndentOpeners: []string{"do", "{", "(", "["},
+            IndentClosers: closers,
+            LineComment:   "#",
+            Quotes:        "\"'`",
+            WordChars:     "_?!",
+        },
+        {
+            Name:        "perl",
+            Extensions:  []string{".pl", ".pm"},
+            IndentUnit:  defaultIndentUnit,
+            LineComment: "#",
+            Quotes:      "\"'",
+            WordChars:   "_",
+        },
+        {
+            Name:        "r",
+            Extensions:  []string{".r"},
+            IndentUnit:  "  ",
+            LineComment: "#",
+            Quotes:      "\"'",
+            WordChars:   "_.",
+        },
+        {
+            Name:          "lua",
+            Extensions:    []string{".lua"},
+            IndentUnit:    "  ",
+            IndentOpeners: []string{"then", "do", "{", "(", "function()"},
+            IndentClosers: []string{"}", ")"},
+            LineComment:   "--",
+            BlockComment:  []string{"--[[", "]]"},
+            Quotes:        "\"'",
+            WordChars:     "_",
+        },
+        {
+            Name:          "sql",
+            Extensions:    []string{".sql"},
+            IndentUnit:    defaultIndentUnit,
+            IndentOpeners: []string{"("},
+            IndentClosers: []string{")"},
+            LineComment:   "--",
+            BlockComment:  cBlock,
+            Quotes:        "'\"",
+            WordChars:     "_",
+        },
+        {
+            Name:         "html",
+            Extensions:   []string{".html", ".htm", ".xml", ".svg"},
+            IndentUnit:   "  ",
+            BlockComment: xmlBlock,
+            Quotes:       "\"",
+            WordChars:    "_-",
+        },
+        {
+            Name:         "markdown",
+            Extensions:   []string{".md", ".markdown"},
+            IndentUnit:   defaultIndentUnit,
+            BlockComment: xmlBlock,
+            WordChars:    "_",
+        },
+        {
+            Name:        "make",
+            Filenames:   []string{"Makefile", "makefile", "GNUmakefile"},
+            Extensions:  []string{".mk"},
+            IndentUnit:  "\t",
+            LineComment: "#",
+            WordChars:   "_-",
+        },
+        {
+            Name:        "dockerfile",
+            Filenames:   []string{"Dockerfile", "Containerfile"},
+            IndentUnit:  defaultIndentUnit,
+            LineComment: "#",
+            Quotes:      "\"'",
+            WordChars:   "_-",
+        },
+    }
+}
+
+// mergeLanguages applies the user's overrides on top of the built-in
+// table. Unknown names are added as new languages.
+func mergeLanguages(langs []*Language, overrides map[string]*Language) []*Language {
+    for name, o := range overrides {
+        if o == nil {
+            continue
+        }
+
+        var lang *Language
+        for _, l := range langs {
+            if l.Name == name {
+                lang = l
+                break
+            }
+        }
+        if lang == nil {
+            lang = &Language{Name: name, IndentUnit: defaultIndentUnit, WordChars: "_"}
+            langs = append(langs, lang)
+        }
+
+        if o.Extensions != nil {
+            lang.Extensions = o.Extensions
+        }
+        if o.Filenames != nil {
+            lang.Filenames = o.Filenames
+        }
+        if o.IndentUnit != "" {
+            lang.IndentUnit = o.IndentUnit
+        }
+        if o.IndentOpeners != nil {
+            lang.IndentOpeners = o.IndentOpeners
+        }
+        if o.IndentClosers != nil {
+            lang.IndentClosers = o.IndentClosers
+        }
+        if o.LineComment != "" {
+            lang.LineComment = o.LineComment
+        }
+        if o.BlockComment != nil {
+            lang.BlockComment = o.BlockComment
+        }
+        if o.Quotes != "" {
+            lang.Quotes = o.Quotes
+        }
+        if o.WordChars != "" {
+            lang.WordChars = o.WordChars
+        }
+        if o.Server != nil {
+            lang.Server = o.Server
+        }
+        if o.Formatter != nil {
+            lang.Formatter = o.Formatter
+        }
+        if o.Fold != "" {
+            lang.Fold = o.Fold
+        }
+    }
+    return langs
+}
+
+func detectLanguage(langs []*Language, filename string) *Language {
+    if filename == "" {
+        return plainText
+    }
+
+    base := filepath.Base(filename)
+    ext := strings.ToLower(filepath.Ext(base))
+
+    for _, lang := range langs {
+        for _, name := range lang.Filenames {
+            if name == base {
+                return lang
+            }
+        }
+    }
+    for _, lang := range langs {
+        for _, e := range lang.Extensions {
+            if e == ext {
+                return lang
+            }
+        }
+    }
+    return plainText
+}
@@ -0,0 +1,241 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+type SortMode int
+
+const (
+    SortLexical SortMode = iota
+    SortNumeric
+    SortNatural
+)
+
+// commitLines stores the new cursor position and records a single undo
+// step for a line operation.
+func (e *Editor) commitLines(tab *Tab, msg string) {
+    e.ensureCursorValid(tab)
+    tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+    e.quitAttempts = 0
+    e.setStatusMsg(msg)
+}
+
+func (e *Editor) moveLines(tab *Tab, delta int) {
+    start, end := tab.selectedLines()
+    if delta < 0 && start == 0 || delta > 0 && end >= tab.buffer.LineCount() {
+        return
+    }
+
+    lines := tab.buffer.GetLines(start, end)
+    if delta < 0 {
+        above := tab.buffer.GetLine(start - 1)
+        tab.buffer.ReplaceLines(start-1, end, append(lines, above))
+    } else {
+        below := tab.buffer.GetLine(end)
+        tab.buffer.ReplaceLines(start, end+1, append([]string{below}, lines...))
+    }
+
+    tab.cursor.Row += delta
+    if tab.selActive {
+        tab.selAnchor.Row += delta
+    }
+    e.commitLines(tab, fmt.Sprintf("Moved %d line(s)", end-start))
+}
+
+func (e *Editor) duplicateLines(tab *Tab) {
+    start, end := tab.selectedLines()
+    lines := tab.buffer.GetLines(start, end)
+    tab.buffer.ReplaceLines(end, end, lines)
+
+    tab.cursor.Row += len(lines)
+    if tab.selActive {
+        tab.selAnchor.Row += len(lines)
+    }
+    e.commitLines(tab, fmt.Sprintf("Duplicated %d line(s)", len(lines)))
+}
+
+func (e *Editor) joinLines(tab *Tab) {
+    start, end := tab.selectedLines()
+    if end-start < 2 {
+        end = start + 2
+    }
+    if end > tab.buffer.LineCount() {
+        e.setStatusMsg("No next line to join")
+        return
+    }
+
+    lines := tab.buffer.GetLines(start, end)
+    joined := lines[0]
+    col := len(joined)
+    for _, next := range lines[1:] {
+        next = strings.TrimLeft(next, " \t")
+        joined = strings.TrimRight(joined, " \t")
+        col = len(joined)
+        if joined != "" && next != "" {
+            joined += " "
+        }
+        joined += next
+    }
+    tab.buffer.ReplaceLines(start, end, []string{joined})
+
+    tab.clearSelection()
+    tab.cursor.Row = start
+    tab.cursor.Col = col
+    e.commitLines(tab, fmt.Sprintf("Joined %d lines", len(lines)))
+}
+
+func (e *Editor) deleteLines(tab *Tab) {
+    start, end := tab.selectedLines()
+    tab.buffer.ReplaceLines(start, end, nil)
+
+    tab.clearSelection()
+    tab.cursor.Row = start
+    tab.cursor.Col = 0
+    e.commitLines(tab, fmt.Sprintf("Deleted %d line(s)", end-start))
+}
+
+// transformLines applies fn to the selected lines. Reordering a single
+// line does nothing, so a selection is required.
+func (e *Editor) transformLines(tab *Tab, what string, fn func([]string) []string) {
+    if !tab.selActive {
+        e.setStatusMsg("No selection")
+        return
+    }
+    start, end := tab.selectedLines()
+
+    lines := fn(tab.buffer.GetLines(start, end))
+    tab.buffer.ReplaceLines(start, end, lines)
+
+    tab.selAnchor = Cursor{Row: start, Col: 0}
+    tab.cursor.Row = start + len(lines) - 1
+    tab.cursor.Col = len(tab.buffer.GetLine(tab.cursor.Row))
+    e.commitLines(tab, fmt.Sprintf("%s %d line(s)", what, end-start))
+}
+
+func (e *Editor) sortLines(tab *Tab, mode SortMode, ignoreCase bool) {
+    e.transformLines(tab, "Sorted", func(lines []string) []string {
+        key := func(s string) string {
+            if ignoreCase {
+                return strings.ToLower(s)
+            }
+            return s
+        }
+
+        sort.SliceStable(lines, func(i, j int) bool {
+            a, b := key(lines[i]), key(lines[j])
+            switch mode {
+            case SortNumeric:
+                na, okA := leadingNumber(a)
+                nb, okB := leadingNumber(b)
+                if okA != okB {
+                    return !okA
+                }
+                if okA && na != nb {
+                    return na < nb
+                }
+                return a < b
+            case SortNatural:
+                return naturalLess(a, b)
+            default:
+                return a < b
+            }
+        })
+        return lines
+    })
+}
+
+func (e *Editor) reverseLines(tab *Tab) {
+    e.transformLines(tab, "Reversed", func(lines []string) []string {
+        for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+            lines[i], lines[j] = lines[j], lines[i]
+        }
+        return lines
+    })
+}
+
+func (e *Editor) uniqueLines(tab *Tab) {
+    e.transformLines(tab, "Deduplicated", func(lines []string) []string {
+        seen := make(map[string]bool, len(lines))
+        result := lines[:0]
+        for _, line := range lines {
+            if !seen[line] {
+                seen[line] = true
+                result = append(result, line)
+            }
+        }
+        return result
+    })
+}
+
+func (e *Editor) shuffleLines(tab *Tab) {
+    e.transformLines(tab, "Shuffled", func(lines []string) []string {
+        rand.Shuffle(len(lines), func(i, j int) {
+            lines[i], lines[j] = lines[j], lines[i]
+        })
+        return lines
+    })
+}
+
+func leadingNumber(s string) (float64, bool) {
+    s = strings.TrimSpace(s)
+    end := 0
+    for end < len(s) {
+        c := s[end]
+        if c >= '0' && c <= '9' || c == '.' || (end == 0 && (c == '-' || c == '+')) {
+            end++
+            continue
+        }
+        break
+    }
+    for end > 0 {
+        if n, err := strconv.ParseFloat(s[:end], 64); err == nil {
+            return n, true
+        }
+        end--
+    }
+    return 0, false
+}
+
+// naturalLess compares strings so that embedded numbers are ordered by
+// value: "file2" sorts before "file10".
+func naturalLess(a, b string) bool {
+    ra, rb := []rune(a), []rune(b)
+    i, j := 0, 0
+    for i < len(ra) && j < len(rb) {
+        if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+            si := i
+            for i < len(ra) && unicode.IsDigit(ra[i]) {
+                i++
+            }
+            sj := j
+            for j < len(rb) && unicode.IsDigit(rb[j]) {
+                j++
+            }
+            na := strings.TrimLeft(string(ra[si:i]), "0")
+            nb := strings.TrimLeft(string(rb[sj:j]), "0")
+            if len(na) != len(nb) {
+                return len(na) < len(nb)
+            }
+            if na != nb {
+                return na < nb
+            }
+            continue
+        }
+        if ra[i] != rb[j] {
+            return ra[i] < rb[j]
+        }
+        i++
+        j++
+    }
+    return len(ra)-i < len(rb)-j
+}
@@ -0,0 +1,607 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+    "unicode/utf16"
+)
+
+const (
+    lspRequestTimeout  = 10 * time.Second
+    lspShutdownTimeout = time.Second
+    maxServerRestarts  = 3
+)
+
+type lspPosition struct {
+    Line      int `json:"line"`
+    Character int `json:"character"`
+}
+
+type lspRange struct {
+    Start lspPosition `json:"start"`
+    End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+    URI   string   `json:"uri"`
+    Range lspRange `json:"range"`
+
+    // Set instead of the above by servers that answer with LocationLinks.
+    TargetURI            string    `json:"targetUri,omitempty"`
+    TargetSelectionRange *lspRange `json:"targetSelectionRange,omitempty"`
+}
+
+type lspTextEdit struct {
+    Range   lspRange `json:"range"`
+    NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+    Changes         map[string][]lspTextEdit `json:"changes,omitempty"`
+    DocumentChanges []struct {
+        TextDocument struct {
+            URI string `json:"uri"`
+        } `json:"textDocument"`
+        Edits []lspTextEdit `json:"edits"`
+    } `json:"documentChanges,omitempty"`
+}
+
+type lspDiagnostic struct {
+    Range    lspRange `json:"range"`
+    Severity int      `json:"severity,omitempty"`
+    Source   string   `json:"source,omitempty"`
+    Message  string   `json:"message"`
+}
+
+type lspCommand struct {
+    Title     string            `json:"title"`
+    Command   string            `json:"command"`
+    Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+type lspMessage struct {
+    ID     json.RawMessage `json:"id,omitempty"`
+    Method string          `json:"method,omitempty"`
+    Params json.RawMessage `json:"params,omitempty"`
+    Result json.RawMessage `json:"result,omitempty"`
+    Error  *struct {
+        Code    int    `json:"code"`
+        Message string `json:"message"`
+    } `json:"error,omitempty"`
+}
+
+// lspDoc is an open document: the version the server knows and the
+// buffer and buffer version it was last sent from.
+type lspDoc struct {
+    version int
+    buffer  *Buffer
+    stamp   int
+}
+
+// lspClient talks to one language server over its stdin and stdout.
+// Messages are written by a single goroutine in the order they are
+// sent; requests block their caller until the response arrives.
+type lspClient struct {
+    language string
+    command  []string
+    root     string
+    started  time.Time
+    restarts int
+    stopping bool
+
+    // utf8 is true when the server counts characters in bytes rather
+    // than UTF-16 code units.
+    utf8 bool
+
+    // docs is only used from the editor's event loop.
+    docs map[string]*lspDoc
+
+    cmd     *exec.Cmd
+    out     chan []byte
+    ready   chan struct{}
+    dead    chan struct{}
+    mu      sync.Mutex
+    nextID  int
+    pending map[int]chan lspMessage
+    err     error
+
+    onNotify  func(method string, params json.RawMessage)
+    onRequest func(id json.RawMessage, method string, params json.RawMessage)
+    onExit    func(err error)
+}
+
+func fileURI(filename string) string {
+    path, err := filepath.Abs(filename)
+    if err != nil {
+        path = filename
+    }
+    return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func uriPath(uri string) string {
+    u, err := url.Parse(uri)
+    if err != nil || u.Scheme != "file" {
+        return uri
+    }
+    return filepath.FromSlash(u.Path)
+}
+
+// character converts a byte column of line to the server's unit.
+func (c *lspClient) character(line string, col int) int {
+    col = clampCol(line, col)
+    if c.utf8 {
+        return col
+    }
+    return len(utf16.Encode([]rune(line[:col])))
+}
+
+// byteCol converts a server character offset in line to a byte column.
+func (c *lspClient) byteCol(line string, ch int) int {
+    if c.utf8 {
+        return clampCol(line, ch)
+    }
+    units := 0
+    for i, r := range line {
+        if units >= ch {
+            return i
+        }
+        units += utf16.RuneLen(r)
+    }
+    return len(line)
+}
+
+// offset converts a server position to a byte offset in text.
+func (c *lspClient) offset(text string, pos lspPosition) int {
+    start := 0
+    for line := 0; line < pos.Line; line++ {
+        i := strings.IndexByte(text[start:], '\n')
+        if i < 0 {
+            return len(text)
+        }
+        start += i + 1
+    }
+    end := strings.IndexByte(text[start:], '\n')
+    if end < 0 {
+        end = len(text) - start
+    }
+    return start + c.byteCol(text[start:start+end], pos.Character)
+}
+
+// applyEdits returns text with the edits applied. Edits must not
+// overlap; inserts at the same position keep their order in edits.
+func (c *lspClient) applyEdits(text string, edits []lspTextEdit) string {
+    type span struct {
+        start, end int
+        text       string
+    }
+    spans := make([]span, len(edits))
+    for i, edit := range edits {
+        spans[i] = span{c.offset(text, edit.Range.Start), c.offset(text, edit.Range.End), edit.NewText}
+    }
+    sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+    var sb strings.Builder
+    pos := 0
+    for _, s := range spans {
+        if s.start < pos {
+            s.start = pos
+        }
+        if s.end < s.start {
+            s.end = s.start
+        }
+        sb.WriteString(text[pos:s.start])
+        sb.WriteString(s.text)
+        pos = s.end
+    }
+    sb.WriteString(text[pos:])
+    return sb.String()
+}
+
+func newLSPClient(language string, command []string, root string) *lspClient {
+    return &lspClient{
+        language: language,
+        command:  command,
+        root:     root,
+        docs:     make(map[string]*lspDoc),
+        out:      make(chan []byte, 256),
+        ready:    make(chan struct{}),
+        dead:     make(chan struct{}),
+        pending:  make(map[int]chan lspMessage),
+    }
+}
+
+// start launches the server and initializes it in the background.
+// Messages sent before the server has answered initialize are held
+// back until it has.
+func (c *lspClient) start() error {
+    c.cmd = exec.Command(c.command[0], c.command[1:]...)
+    c.cmd.Dir = c.root
+    stdin, err := c.cmd.StdinPipe()
+    if err != nil {
+        return err
+    }
+    stdout, err := c.cmd.StdoutPipe()
+    if err != nil {
+        return err
+    }
+    if err := c.cmd.Start(); err != nil {
+        return err
+    }
+    c.started = time.Now()
+
+    go c.readLoop(bufio.NewReader(stdout))
+    go c.writeLoop(stdin)
+    id, ch := c.request("initialize", c.initializeParams())
+    go func() {
+        var result struct {
+            Capabilities struct {
+                PositionEncoding string `json:"positionEncoding"`
+            } `json:"capabilities"`
+        }
+        if err := c.wait("initialize", id, ch, &result); err != nil {
+            c.kill(fmt.Errorf("initialize: %v", err))
+            return
+        }
+        c.utf8 = result.Capabilities.PositionEncoding == "utf-8"
+        close(c.ready)
+    }()
+    return nil
+}
+
+func (c *lspClient) initializeParams() interface{} {
+    kinds := []string{"quickfix", "refactor", "refactor.extract", "refactor.inline", "refactor.rewrite", "source", "source.organizeImports"}
+    return map[string]interface{}{
+        "processId":        os.Getpid(),
+        "clientInfo":       map[string]string{"name": "goedit"},
+        "rootUri":          fileURI(c.root),
+        "workspaceFolders": []map[string]string{{"uri": fileURI(c.root), "name": filepath.Base(c.root)}},
+        "capabilities": map[string]interface{}{
+            "general": map[string]interface{}{"positionEncodings": []string{"utf-8", "utf-16"}},
+            "workspace": map[string]interface{}{
+                "applyEdit":     true,
+                "configuration": true,
+            },
+            "textDocument": map[string]interface{}{
+                "synchronization":    map[string]interface{}{"didSave": true},
+                "publishDiagnostics": map[string]interface{}{},
+                "hover":              map[string]interface{}{"contentFormat": []string{"plaintext", "markdown"}},
+                "completion":         map[string]interface{}{"completionItem": map[string]interface{}{"snippetSupport": false}},
+                "definition":         map[string]interface{}{},
+                "references":         map[string]interface{}{},
+                "rename":             map[string]interface{}{},
+                "codeAction": map[string]interface{}{
+                    "codeActionLiteralSupport": map[string]interface{}{"codeActionKind": map[string]interface{}{"valueSet": kinds}},
+                    "resolveSupport":           map[string]interface{}{"properties": []string{"edit"}},
+                },
+            },
+        },
+    }
+}
+
+// writeLoop writes the queued messages. Only initialize may go out
+// before the server has answered it, and initialized must come next.
+func (c *lspClient) writeLoop(w io.WriteCloser) {
+    defer w.Close()
+    write := func(data []byte) bool {
+        _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+        return err == nil
+    }
+
+    select {
+    case data := <-c.out:
+        if !write(data) {
+            return
+        }
+    case <-c.dead:
+        return
+    }
+    select {
+    case <-c.ready:
+        if !write([]byte(`{"jsonrpc":"2.0","method":"initialized","params":{}}`)) {
+            return
+        }
+    case <-c.dead:
+        return
+    }
+
+    for {
+        select {
+        case data := <-c.out:
+            if !write(data) {
+                return
+            }
+        case <-c.dead:
+            return
+        }
+    }
+}
+
+func (c *lspClient) readLoop(r *bufio.Reader) {
+    for {
+        msg, err := readLSPMessage(r)
+        if err != nil {
+            c.kill(err)
+            return
+        }
+        switch {
+        case msg.Method != "" && len(msg.ID) > 0:
+            if c.onRequest != nil {
+                c.onRequest(msg.ID, msg.Method, msg.Params)
+            } else {
+                c.reply(msg.ID, nil, fmt.Errorf("unsupported request %s", msg.Method))
+            }
+        case msg.Method != "":
+            if c.onNotify != nil {
+                c.onNotify(msg.Method, msg.Params)
+            }
+        default:
+            id, err := strconv.Atoi(string(msg.ID))
+            if err != nil {
+                continue
+            }
+            c.mu.Lock()
+            ch := c.pending[id]
+            delete(c.pending, id)
+            c.mu.Unlock()
+            if ch != nil {
+                ch <- *msg
+            }
+        }
+    }
+}
+
+func readLSPMessage(r *bufio.Reader) (*lspMessage, error) {
+    length := -1
+    for {
+        line, err := r.ReadString('\n')
+        if err != nil {
+            return nil, err
+        }
+        line = strings.TrimSpace(line)
+        if line == "" {
+            break
+        }
+        if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Content-Length") {
+            if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+                return nil, fmt.Errorf("bad header %q", line)
+            }
+        }
+    }
+    if length < 0 {
+        return nil, fmt.Errorf("missing Content-Length")
+    }
+    data := make([]byte, length)
+    if _, err := io.ReadFull(r, data); err != nil {
+        return nil, err
+    }
+    var msg lspMessage
+    if err := json.Unmarshal(data, &msg); err != nil {
+        return nil, err
+    }
+    return &msg, nil
+}
+
+// kill ends the session after an error or when the server exits. All
+// waiting requests fail and onExit is called once.
+func (c *lspClient) kill(err error) {
+    c.mu.Lock()
+    select {
+    case <-c.dead:
+        c.mu.Unlock()
+        return
+    default:
+    }
+    c.err = err
+    close(c.dead)
+    c.pending = make(map[int]chan lspMessage)
+    c.mu.Unlock()
+
+    if c.cmd != nil && c.cmd.Process != nil {
+        c.cmd.Process.Kill()
+        c.cmd.Wait()
+    }
+    if c.onExit != nil {
+        c.onExit(err)
+    }
+}
+
+// isReady reports whether the server has been initialized.
+func (c *lspClient) isReady() bool {
+    select {
+    case <-c.ready:
+        return true
+    default:
+        return false
+    }
+}
+
+func (c *lspClient) alive() bool {
+    select {
+    case <-c.dead:
+        return false
+    default:
+        return true
+    }
+}
+
+func (c *lspClient) send(msg map[string]interface{}) {
+    msg["jsonrpc"] = "2.0"
+    data, err := json.Marshal(msg)
+    if err != nil {
+        return
+    }
+    select {
+    case c.out <- data:
+    case <-c.dead:
+    }
+}
+
+func (c *lspClient) notify(method string, params interface{}) {
+    c.send(map[string]interface{}{"method": method, "params": params})
+}
+
+// reply answers a request from the server.
+func (c *lspClient) reply(id json.RawMessage, result interface{}, err error) {
+    msg := map[string]interface{}{"id": id, "result": result}
+    if err != nil {
+        delete(msg, "result")
+        msg["error"] = map[string]interface{}{"code": -32601, "message": err.Error()}
+    }
+    c.send(msg)
+}
+
+// call sends a request and decodes the response into result, which may
+// be nil.
+func (c *lspClient) call(method string, params interface{}, result interface{}) error {
+    id, ch := c.request(method, params)
+    return c.wait(method, id, ch, result)
+}
+
+func (c *lspClient) request(method string, params interface{}) (int, chan lspMessage) {
+    c.mu.Lock()
+    c.nextID++
+    id := c.nextID
+    ch := make(chan lspMessage, 1)
+    c.pending[id] = ch
+    c.mu.Unlock()
+
+    c.send(map[string]interface{}{"id": id, "method": method, "params": params})
+    return id, ch
+}
+
+func (c *lspClient) wait(method string, id int, ch chan lspMessage, result interface{}) error {
+    select {
+    case msg := <-ch:
+        if msg.Error != nil {
+            return fmt.Errorf("%s", msg.Error.Message)
+        }
+        if result == nil || len(msg.Result) == 0 {
+            return nil
+        }
+        return json.Unmarshal(msg.Result, result)
+    case <-c.dead:
+        return fmt.Errorf("%s is not running", c.command[0])
+    case <-time.After(lspRequestTimeout):
+        c.mu.Lock()
+        delete(c.pending, id)
+        c.mu.Unlock()
+        return fmt.Errorf("%s did not answer %s", c.command[0], method)
+    }
+}
+
+// stop shuts the server down politely, killing it if it does not exit
+// in time.
+func (c *lspClient) stop() {
+    c.stopping = true
+    if !c.alive() {
+        return
+    }
+    done := make(chan struct{})
+    go func() {
+        select {
+        case <-c.ready:
+            c.call("shutdown", nil, nil)
+            c.notify("exit", nil)
+        case <-c.dead:
+        }
+        close(done)
+    }()
+    select {
+    case <-done:
+        select {
+        case <-c.dead:
+        case <-time.After(lspShutdownTimeout):
+        }
+    case <-time.After(lspShutdownTimeout):
+    }
+    c.kill(fmt.Errorf("stopped"))
+}
+
+// sync sends the text of a buffer when it has changed since the server
+// last saw it, opening the document first if needed.
+func (c *lspClient) sync(uri, languageID string, b *Buffer) {
+    doc := c.docs[uri]
+    if doc == nil {
+        c.docs[uri] = &lspDoc{version: 1, buffer: b, stamp: b.version}
+        c.notify("textDocument/didOpen", map[string]interface{}{
+            "textDocument": map[string]interface{}{"uri": uri, "languageId": languageID, "version": 1, "text": b.GetText()},
+        })
+        return
+    }
+    if doc.buffer == b && doc.stamp == b.version {
+        return
+    }
+    text := b.GetText()
+    doc.version++
+    doc.buffer, doc.stamp = b, b.version
+    c.notify("textDocument/didChange", map[string]interface{}{
+        "textDocument":   map[string]interface{}{"uri": uri, "version": doc.version},
+        "contentChanges": []map[string]string{{"text": text}},
+    })
+}
+
+func (c *lspClient) close(uri string) {
+    delete(c.docs, uri)
+    c.notify("textDocument/didClose", map[string]interface{}{"textDocument": map[string]string{"uri": uri}})
+}
+
+// decodeMarkup flattens hover contents, which can be a string, a
+// MarkupContent, a MarkedString or a list of those.
+func decodeMarkup(raw json.RawMessage) string {
+    var s string
+    if json.Unmarshal(raw, &s) == nil {
+        return s
+    }
+    var markup struct {
+        Value string `json:"value"`
+    }
+    if json.Unmarshal(raw, &markup) == nil && markup.Value != "" {
+        return markup.Value
+    }
+    var list []json.RawMessage
+    if json.Unmarshal(raw, &list) == nil {
+        parts := make([]string, 0, len(list))
+        for _, item := range list {
+            parts = append(parts, decodeMarkup(item))
+        }
+        return strings.Join(parts, "\n")
+    }
+    return ""
+}
+
+// decodeLocations reads a definition or references result, which can
+// be a Location, a list of Locations or a list of LocationLinks.
+func decodeLocations(raw json.RawMessage) []lspLocation {
+    var list []lspLocation
+    if json.Unmarshal(raw, &list) != nil {
+        var single lspLocation
+        if json.Unmarshal(raw, &single) != nil || single.URI == "" {
+            return nil
+        }
+        list = []lspLocation{single}
+    }
+    for i, loc := range list {
+        if loc.TargetURI != "" {
+            list[i].URI = loc.TargetURI
+            if loc.TargetSelectionRange != nil {
+                list[i].Range = *loc.TargetSelectionRange
+            }
+        }
+    }
+    return list
+}
@@ -0,0 +1,947 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const maxHoverRows = 12
+
+// diagnostic is a server diagnostic converted to buffer positions.
+type diagnostic struct {
+    row, col       int
+    endRow, endCol int
+    severity       int
+    message        string
+    raw            lspDiagnostic
+}
+
+// covers reports whether the diagnostic underlines the given cell. An
+// empty range marks the single character at its start.
+func (d diagnostic) covers(row, col int) bool {
+    if d.row == d.endRow && d.col == d.endCol {
+        return row == d.row && col == d.col
+    }
+    if row < d.row || row == d.row && col < d.col {
+        return false
+    }
+    return row < d.endRow || row == d.endRow && col < d.endCol
+}
+
+func severityColor(severity int) tcell.Color {
+    switch severity {
+    case 1:
+        return tcell.ColorRed
+    case 2:
+        return tcell.ColorYellow
+    case 3:
+        return tcell.ColorBlue
+    default:
+        return tcell.ColorGray
+    }
+}
+
+func severityName(severity int) string {
+    switch severity {
+    case 1:
+        return "error"
+    case 2:
+        return "warning"
+    case 3:
+        return "info"
+    default:
+        return "hint"
+    }
+}
+
+type codeActionMenu struct {
+    popup   *Popup
+    actions []json.RawMessage
+    client  *lspClient
+}
+
+// runOnMain queues fn to run on the event loop, which owns all editor
+// state, and wakes the loop up.
+func (e *Editor) runOnMain(fn func()) {
+    e.mainMu.Lock()
+    e.mainQueue = append(e.mainQueue, fn)
+    e.mainMu.Unlock()
+    e.redraw()
+}
+
+func (e *Editor) runQueued() {
+    e.mainMu.Lock()
+    queue := e.mainQueue
+    e.mainQueue = nil
+    e.mainMu.Unlock()
+    for _, fn := range queue {
+        fn()
+    }
+}
+
+// languageServer returns the server for the tab's language, starting
+// it on first use. It returns nil for unnamed buffers, languages without
+// a configured server and servers that could not be kept running.
+func (e *Editor) languageServer(tab *Tab) *lspClient {
+    if tab == nil || tab.buffer == nil || tab.buffer.filename == "" {
+        return nil
+    }
+    lang := e.languageFor(tab)
+    if len(lang.Server) == 0 || e.lspFailed[lang.Name] {
+        return nil
+    }
+    if c := e.lspClients[lang.Name]; c != nil {
+        return c
+    }
+    return e.startLanguageServer(lang, 0)
+}
+
+func (e *Editor) startLanguageServer(lang *Language, restarts int) *lspClient {
+    if e.lspClients == nil {
+        e.lspClients = make(map[string]*lspClient)
+        e.lspFailed = make(map[string]bool)
+        e.diagnostics = make(map[string][]diagnostic)
+    }
+    root, err := os.Getwd()
+    if err != nil {
+        root = "."
+    }
+
+    c := newLSPClient(lang.Name, lang.Server, root)
+    c.restarts = restarts
+    c.onNotify = func(method string, params json.RawMessage) {
+        e.runOnMain(func() { e.handleServerNotification(c, method, params) })
+    }
+    c.onRequest = func(id json.RawMessage, method string, params json.RawMessage) {
+        e.runOnMain(func() { e.handleServerRequest(c, id, method, params) })
+    }
+    c.onExit = func(err error) {
+        e.runOnMain(func() { e.serverExited(c, err) })
+    }
+    if err := c.start(); err != nil {
+        e.lspFailed[lang.Name] = true
+        e.setStatusMsg(fmt.Sprintf("Cannot start %s: %v", lang.Server[0], err))
+        return nil
+    }
+    e.lspClients[lang.Name] = c
+    return c
+}
+
+// serverExited restarts a server that went away unexpectedly, unless it
+// keeps crashing shortly after starting.
+func (e *Editor) serverExited(c *lspClient, err error) {
+    if e.lspClients[c.language] != c {
+        return
+    }
+    delete(e.lspClients, c.language)
+    for uri := range c.docs {
+        delete(e.diagnostics, uri)
+    }
+    if c.stopping {
+        return
+    }
+
+    restarts := c.restarts
+    if time.Since(c.started) > time.Minute {
+        restarts = 0
+    }
+    if restarts >= maxServerRestarts {
+        e.lspFailed[c.language] = true
+        e.setStatusMsg(fmt.Sprintf("%s stopped (%v). lsp-restart starts it again", c.command[0], err))
+        return
+    }
+    for _, lang := range e.languages {
+        if lang.Name == c.language {
+            e.setStatusMsg(fmt.Sprintf("%s exited (%v), restarting", c.command[0], err))
+            e.startLanguageServer(lang, restarts+1)
+            e.syncDocuments()
+            return
+        }
+    }
+}
+
+// restartLanguageServer stops the server of the tab's language and
+// starts a fresh one, also after it has given up restarting.
+func (e *Editor) restartLanguageServer(tab *Tab) error {
+    lang := e.languageFor(tab)
+    if len(lang.Server) == 0 {
+        return fmt.Errorf("no language server configured for %s", lang.Name)
+    }
+    if c := e.lspClients[lang.Name]; c != nil {
+        c.stop()
+        e.serverExited(c, nil)
+    }
+    delete(e.lspFailed, lang.Name)
+    if e.languageServer(tab) == nil {
+        return fmt.Errorf("%s could not be started", lang.Server[0])
+    }
+    e.syncDocuments()
+    e.setStatusMsg(fmt.Sprintf("Restarted %s", lang.Server[0]))
+    return nil
+}
+
+func (e *Editor) stopLanguageServers() {
+    var wg sync.WaitGroup
+    for _, c := range e.lspClients {
+        wg.Add(1)
+        go func(c *lspClient) {
+            defer wg.Done()
+            c.stop()
+        }(c)
+    }
+    wg.Wait()
+}
+
+// syncDocuments opens every file shown in a tab on its language server,
+// sends the text of changed buffers and closes documents whose tab is
+// gone. It runs after every event, so the servers follow all edits.
+func (e *Editor) syncDocuments() {
+    open := make(map[*lspClient]map[string]bool)
+    for _, tab := range e.tabManager.tabs {
+        c := e.languageServer(tab)
+        if c == nil {
+            continue
+        }
+        uri := fileURI(tab.buffer.filename)
+        c.sync(uri, c.language, tab.buffer)
+        if open[c] == nil {
+            open[c] = make(map[string]bool)
+        }
+        open[c][uri] = true
+    }
+    for _, c := range e.lspClients {
+        for uri := range c.docs {
+            if !open[c][uri] {
+                c.close(uri)
+                delete(e.diagnostics, uri)
+            }
+        }
+    }
+}
+
+func (e *Editor) notifySaved(tab *Tab) {
+    c := e.languageServer(tab)
+    if c == nil {
+        return
+    }
+    e.syncDocuments()
+    c.notify("textDocument/didSave", map[string]interface{}{"textDocument": map[string]string{"uri": fileURI(tab.buffer.filename)}})
+}
+
+func (e *Editor) handleServerNotification(c *lspClient, method string, params json.RawMessage) {
+    switch method {
+    case "textDocument/publishDiagnostics":
+        var p struct {
+            URI         string          `json:"uri"`
+            Diagnostics []lspDiagnostic `json:"diagnostics"`
+        }
+        if json.Unmarshal(params, &p) == nil {
+            e.setDiagnostics(c, p.URI, p.Diagnostics)
+        }
+    case "window/showMessage":
+        var p struct {
+            Type    int    `json:"type"`
+            Message string `json:"message"`
+        }
+        if json.Unmarshal(params, &p) == nil && p.Type <= 2 {
+            e.setStatusMsg(fmt.Sprintf("%s: %s", c.command[0], p.Message))
+        }
+    }
+}
+
+func (e *Editor) handleServerRequest(c *lspClient, id json.RawMessage, method string, params json.RawMessage) {
+    switch method {
+    case "workspace/applyEdit":
+        var p struct {
+            Edit lspWorkspaceEdit `json:"edit"`
+        }
+        if err := json.Unmarshal(params, &p); err != nil {
+            c.reply(id, nil, err)
+            return
+        }
+        if _, err := e.applyWorkspaceEdit(c, p.Edit); err != nil {
+            c.reply(id, map[string]interface{}{"applied": false, "failureReason": err.Error()}, nil)
+            e.setStatusMsg(err.Error())
+            return
+        }
+        c.reply(id, map[string]bool{"applied": true}, nil)
+    case "workspace/configuration":
+        var p struct {
+            Items []json.RawMessage `json:"items"`
+        }
+        json.Unmarshal(params, &p)
+        c.reply(id, make([]interface{}, len(p.Items)), nil)
+    case "client/registerCapability", "client/unregisterCapability", "window/workDoneProgress/create", "window/showMessageRequest":
+        c.reply(id, nil, nil)
+    default:
+        c.reply(id, nil, fmt.Errorf("unsupported request %s", method))
+    }
+}
+
+// setDiagnostics stores the diagnostics of an open document in buffer
+// positions.
+func (e *Editor) setDiagnostics(c *lspClient, uri string, diags []lspDiagnostic) {
+    index := e.tabManager.FindFile(uriPath(uri))
+    if index < 0 || len(diags) == 0 {
+        delete(e.diagnostics, uri)
+        return
+    }
+    b := e.tabManager.tabs[index].buffer
+
+    list := make([]diagnostic, len(diags))
+    for i, d := range diags {
+        start, end := d.Range.Start, d.Range.End
+        list[i] = diagnostic{
+            row:      start.Line,
+            col:      c.byteCol(b.GetLine(start.Line), start.Character),
+            endRow:   end.Line,
+            endCol:   c.byteCol(b.GetLine(end.Line), end.Character),
+            severity: d.Severity,
+            message:  d.Message,
+            raw:      d,
+        }
+        if d.Source != "" {
+            list[i].message = d.Source + ": " + d.Message
+        }
+    }
+    sort.SliceStable(list, func(i, j int) bool {
+        if list[i].row != list[j].row {
+            return list[i].row < list[j].row
+        }
+        return list[i].col < list[j].col
+    })
+    e.diagnostics[uri] = list
+}
+
+func (e *Editor) diagnosticsFor(tab *Tab) []diagnostic {
+    if len(e.diagnostics) == 0 || tab.buffer.filename == "" {
+        return nil
+    }
+    return e.diagnostics[fileURI(tab.buffer.filename)]
+}
+
+// gutterWidth is the width of the diagnostics column, shown for files
+// that have a running language server.
+func (e *Editor) gutterWidth() int {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.buffer.filename == "" || len(e.lspClients) == 0 {
+        return 0
+    }
+    if e.lspClients[e.languageFor(tab).Name] == nil {
+        return 0
+    }
+    return 2
+}
+
+// drawGutter marks a line with the most severe of its diagnostics.
+func (e *Editor) drawGutter(row, y int) {
+    if e.gutterWidth() == 0 {
+        return
+    }
+    severity := 0
+    for _, d := range e.diagMarks {
+        if d.row == row && (severity == 0 || d.severity < severity) {
+            severity = d.severity
+        }
+    }
+    if severity > 0 {
+        mark := rune(strings.ToUpper(severityName(severity))[0])
+        e.screen.SetContent(0, y, mark, nil, tcell.StyleDefault.Foreground(severityColor(severity)).Bold(true))
+    }
+}
+
+// nextDiagnostic moves to the next (or previous) diagnostic, wrapping
+// around, and shows its message.
+func (e *Editor) nextDiagnostic(tab *Tab, backward bool) {
+    diags := e.diagnosticsFor(tab)
+    if len(diags) == 0 {
+        e.setStatusMsg("No diagnostics")
+        return
+    }
+
+    target := -1
+    for i, d := range diags {
+        after := d.row > tab.cursor.Row || d.row == tab.cursor.Row && d.col > tab.cursor.Col
+        before := d.row < tab.cursor.Row || d.row == tab.cursor.Row && d.col < tab.cursor.Col
+        if !backward && after {
+            target = i
+            break
+        }
+        if backward && before {
+            target = i
+        }
+    }
+    if target < 0 {
+        target = 0
+        if backward {
+            target = len(diags) - 1
+        }
+    }
+
+    d := diags[target]
+    tab.clearSelection()
+    tab.cursor.Row, tab.cursor.Col = d.row, d.col
+    e.ensureCursorValid(tab)
+    e.setStatusMsg(fmt.Sprintf("%s (%d/%d): %s", severityName(d.severity), target+1, len(diags), d.message))
+}
+
+// lspRequest sends a request about the tab's document in the background
+// and calls done on the event loop once the result has been decoded.
+// Errors are shown in the status bar instead.
+func (e *Editor) lspRequest(tab *Tab, method string, params func(c *lspClient) interface{}, result interface{}, done func(c *lspClient)) bool {
+    c := e.languageServer(tab)
+    if c == nil {
+        e.setStatusMsg("No language server for this file")
+        return false
+    }
+    if !c.isReady() {
+        e.setStatusMsg(fmt.Sprintf("%s is still starting", c.command[0]))
+        return false
+    }
+    e.syncDocuments()
+    p := params(c)
+    e.setStatusMsg(fmt.Sprintf("Waiting for %s...", c.command[0]))
+    go func() {
+        err := c.call(method, p, result)
+        e.runOnMain(func() {
+            if err != nil {
+                e.setStatusMsg(fmt.Sprintf("%s: %v", c.command[0], err))
+                return
+            }
+            done(c)
+        })
+    }()
+    return true
+}
+
+func positionParams(c *lspClient, tab *Tab) map[string]interface{} {
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    return map[string]interface{}{
+        "textDocument": map[string]string{"uri": fileURI(tab.buffer.filename)},
+        "position":     lspPosition{tab.cursor.Row, c.character(line, tab.cursor.Col)},
+    }
+}
+
+// hoverInfo shows the server's description of the symbol at the cursor
+// in a popup that closes on the next key.
+func (e *Editor) hoverInfo(tab *Tab) {
+    var result struct {
+        Contents json.RawMessage `json:"contents"`
+    }
+    e.lspRequest(tab, "textDocument/hover", func(c *lspClient) interface{} {
+        return positionParams(c, tab)
+    }, &result, func(c *lspClient) {
+        var items []PopupItem
+        for _, line := range strings.Split(decodeMarkup(result.Contents), "\n") {
+            line = strings.TrimRight(line, " \r")
+            if strings.HasPrefix(line, "```") || line == "" && len(items) == 0 {
+                continue
+            }
+            items = append(items, PopupItem{Label: strings.ReplaceAll(line, "\t", "    ")})
+        }
+        for len(items) > 0 && items[len(items)-1].Label == "" {
+            items = items[:len(items)-1]
+        }
+        if len(items) == 0 {
+            e.setStatusMsg("No information at the cursor")
+            return
+        }
+
+        e.setStatusMsg("")
+        if len(items) > maxHoverRows {
+            e.setStatusMsg(fmt.Sprintf("%d more lines not shown", len(items)-maxHoverRows))
+            items = items[:maxHoverRows]
+        }
+        e.hover = NewPopup(items, len(items))
+        e.hover.Selected = -1
+    })
+}
+
+func (e *Editor) gotoDefinition(tab *Tab) {
+    var result json.RawMessage
+    e.lspRequest(tab, "textDocument/definition", func(c *lspClient) interface{} {
+        return positionParams(c, tab)
+    }, &result, func(c *lspClient) {
+        locations := decodeLocations(result)
+        if len(locations) == 0 {
+            e.setStatusMsg("No definition found")
+            return
+        }
+        e.jumpToLocation(c, locations[0])
+    })
+}
+
+// relPath shortens an absolute path below the working directory.
+func relPath(path string) string {
+    if cwd, err := os.Getwd(); err == nil {
+        if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+            return rel
+        }
+    }
+    return path
+}
+
+// jumpToLocation opens the file of a location, or switches to its tab,
+// and moves the cursor there.
+func (e *Editor) jumpToLocation(c *lspClient, loc lspLocation) {
+    path := relPath(uriPath(loc.URI))
+    if err := e.openFile(path); err != nil {
+        e.setStatusMsg(fmt.Sprintf("Failed to open %s: %v", path, err))
+        return
+    }
+    tab := e.tabManager.GetActiveTab()
+    tab.clearSelection()
+    tab.cursor.Row = loc.Range.Start.Line
+    e.ensureCursorValid(tab)
+    tab.cursor.Col = c.byteCol(tab.buffer.GetLine(tab.cursor.Row), loc.Range.Start.Character)
+    e.setStatusMsg(fmt.Sprintf("%s:%d", path, tab.cursor.Row+1))
+}
+
+// findReferences lists the references to the symbol at the cursor in
+// the grep results view.
+func (e *Editor) findReferences(tab *Tab) {
+    _, word := e.wordAt(tab)
+    var result []lspLocation
+    e.lspRequest(tab, "textDocument/references", func(c *lspClient) interface{} {
+        p := positionParams(c, tab)
+        p["context"] = map[string]bool{"includeDeclaration": true}
+        return p
+    }, &result, func(c *lspClient) {
+        if len(result) == 0 {
+            e.setStatusMsg("No references found")
+            return
+        }
+        e.showLocations(c, fmt.Sprintf("References to %s", word), result)
+    })
+}
+
+// showLocations fills the grep results view with server locations,
+// taking the preview lines from open buffers or from disk.
+func (e *Editor) showLocations(c *lspClient, title string, locations []lspLocation) {
+    files := make(map[string][]string)
+    linesOf := func(path string) []string {
+        if lines, ok := files[path]; ok {
+            return lines
+        }
+        var lines []string
+        if index := e.tabManager.FindFile(path); index >= 0 {
+            b := e.tabManager.tabs[index].buffer
+            lines = b.GetLines(0, b.LineCount())
+        } else {
+            lines, _, _ = readLines(path)
+        }
+        files[path] = lines
+        return lines
+    }
+
+    var results []grepResult
+    for _, loc := range locations {
+        path := uriPath(loc.URI)
+        lines := linesOf(path)
+        row := loc.Range.Start.Line
+        if row >= len(lines) {
+            continue
+        }
+        line := strings.TrimRight(lines[row], "\r")
+        col := c.byteCol(line, loc.Range.Start.Character)
+        end := len(line)
+        if loc.Range.End.Line == row {
+            end = c.byteCol(line, loc.Range.End.Character)
+        }
+        preview := strings.TrimLeft(line, " \t")
+        results = append(results, grepResult{
+            path: relPath(path), row: row, col: col, length: end - col,
+            preview: preview, offset: len(line) - len(preview),
+        })
+    }
+    sort.SliceStable(results, func(i, j int) bool {
+        a, b := results[i], results[j]
+        if a.path != b.path {
+            return a.path < b.path
+        }
+        if a.row != b.row {
+            return a.row < b.row
+        }
+        return a.col < b.col
+    })
+
+    cancel := make(chan struct{})
+    close(cancel)
+    if e.grep != nil {
+        e.grep.stop()
+    }
+    e.grep = &grepSearch{query: title, results: results, done: true, cancel: cancel}
+    e.mode = ModeGrepResults
+}
+
+// wordAt returns the start and text of the word under the cursor.
+func (e *Editor) wordAt(tab *Tab) (int, string) {
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    lang := e.languageFor(tab)
+    start, end := clampCol(line, tab.cursor.Col), clampCol(line, tab.cursor.Col)
+    for start > 0 {
+        r, size := utf8.DecodeLastRuneInString(line[:start])
+        if !lang.isWordChar(r) {
+            break
+        }
+        start -= size
+    }
+    for end < len(line) {
+        r, size := utf8.DecodeRuneInString(line[end:])
+        if !lang.isWordChar(r) {
+            break
+        }
+        end += size
+    }
+    return start, line[start:end]
+}
+
+func (e *Editor) startRename(tab *Tab) {
+    if e.languageServer(tab) == nil {
+        e.setStatusMsg("No language server for this file")
+        return
+    }
+    _, word := e.wordAt(tab)
+    if word == "" {
+        e.setStatusMsg("No symbol at the cursor")
+        return
+    }
+    e.mode = ModeRename
+    e.inputBuffer = word
+    e.setStatusMsg(fmt.Sprintf("Rename '%s' to: %s", word, e.inputBuffer))
+}
+
+func (e *Editor) handleRenameMode(ev *tcell.EventKey) bool {
+    tab := e.tabManager.GetActiveTab()
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.setStatusMsg("Rename cancelled")
+    case tcell.KeyEnter:
+        e.mode = ModeNormal
+        if e.inputBuffer == "" {
+            e.setStatusMsg("No name entered")
+            return true
+        }
+        e.rename(tab, e.inputBuffer)
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg("Rename to: " + e.inputBuffer)
+    case tcell.KeyRune:
+        e.inputBuffer += string(ev.Rune())
+        e.setStatusMsg("Rename to: " + e.inputBuffer)
+    }
+    return true
+}
+
+// rename renames the symbol at the cursor in all files that use it.
+func (e *Editor) rename(tab *Tab, name string) {
+    var result lspWorkspaceEdit
+    e.lspRequest(tab, "textDocument/rename", func(c *lspClient) interface{} {
+        p := positionParams(c, tab)
+        p["newName"] = name
+        return p
+    }, &result, func(c *lspClient) {
+        files, err := e.applyWorkspaceEdit(c, result)
+        if err != nil {
+            e.setStatusMsg(fmt.Sprintf("Rename failed: %v", err))
+            return
+        }
+        e.setStatusMsg(fmt.Sprintf("Renamed to %s in %d file(s). undo-replace reverts one file at a time", name, files))
+    })
+}
+
+// applyWorkspaceEdit applies server edits. Open files change in their
+// buffers as one undo step each, other files are written to disk; both
+// can be reverted with undo-replace.
+func (e *Editor) applyWorkspaceEdit(c *lspClient, edit lspWorkspaceEdit) (int, error) {
+    changes := make(map[string][]lspTextEdit)
+    for uri, edits := range edit.Changes {
+        changes[uri] = append(changes[uri], edits...)
+    }
+    for _, dc := range edit.DocumentChanges {
+        changes[dc.TextDocument.URI] = append(changes[dc.TextDocument.URI], dc.Edits...)
+    }
+    uris := make([]string, 0, len(changes))
+    for uri := range changes {
+        uris = append(uris, uri)
+    }
+    sort.Strings(uris)
+
+    files := 0
+    for _, uri := range uris {
+        path := uriPath(uri)
+        f := &fileReplace{path: relPath(path)}
+        if index := e.tabManager.FindFile(path); index >= 0 {
+            f.tab = e.tabManager.tabs[index]
+            f.lines = f.tab.buffer.GetLines(0, f.tab.buffer.LineCount())
+        } else {
+            lines, charset, err := readLines(path)
+            if err != nil {
+                return files, fmt.Errorf("%s: %v", f.path, err)
+            }
+            f.lines, f.charset = lines, charset
+        }
+
+        text := strings.Join(f.lines, "\n")
+        updated := c.applyEdits(text, changes[uri])
+        if updated == text {
+            continue
+        }
+        if err := e.writeReplacement(f, strings.Split(updated, "\n")); err != nil {
+            return files, fmt.Errorf("%s: %v", f.path, err)
+        }
+        files++
+    }
+    e.syncDocuments()
+    return files, nil
+}
+
+// codeAction asks for the fixes and refactorings available at the
+// cursor or for the selection, and shows them in a popup.
+func (e *Editor) codeAction(tab *Tab) {
+    var result []json.RawMessage
+    e.lspRequest(tab, "textDocument/codeAction", func(c *lspClient) interface{} {
+        startRow, startCol, endRow, endCol, ok := tab.selectionRange()
+        if !ok {
+            startRow, startCol, endRow, endCol = tab.cursor.Row, tab.cursor.Col, tab.cursor.Row, tab.cursor.Col
+        }
+        r := lspRange{
+            Start: lspPosition{startRow, c.character(tab.buffer.GetLine(startRow), startCol)},
+            End:   lspPosition{endRow, c.character(tab.buffer.GetLine(endRow), endCol)},
+        }
+        diags := []lspDiagnostic{}
+        for _, d := range e.diagnosticsFor(tab) {
+            if d.row <= endRow && d.endRow >= startRow {
+                diags = append(diags, d.raw)
+            }
+        }
+        return map[string]interface{}{
+            "textDocument": map[string]string{"uri": fileURI(tab.buffer.filename)},
+            "range":        r,
+            "context":      map[string]interface{}{"diagnostics": diags},
+        }
+    }, &result, func(c *lspClient) {
+        var items []PopupItem
+        var actions []json.RawMessage
+        for _, raw := range result {
+            var a struct {
+                Title    string          `json:"title"`
+                Kind     string          `json:"kind"`
+                Disabled json.RawMessage `json:"disabled"`
+            }
+            if json.Unmarshal(raw, &a) != nil || a.Title == "" || a.Disabled != nil {
+                continue
+            }
+            items = append(items, PopupItem{Label: a.Title, Detail: a.Kind})
+            actions = append(actions, raw)
+        }
+        if len(items) == 0 {
+            e.setStatusMsg("No code actions available here")
+            return
+        }
+        e.codeActions = &codeActionMenu{popup: NewPopup(items, 10), actions: actions, client: c}
+        e.mode = ModeCodeAction
+        e.setStatusMsg("Choose a code action: Enter applies, Esc cancels")
+    })
+}
+
+func (e *Editor) handleCodeActionMode(ev *tcell.EventKey) bool {
+    m := e.codeActions
+    if m == nil {
+        e.mode = ModeNormal
+        return true
+    }
+    switch ev.Key() {
+    case tcell.KeyUp:
+        m.popup.Move(-1)
+    case tcell.KeyDown:
+        m.popup.Move(1)
+    case tcell.KeyEscape:
+        e.codeActions = nil
+        e.mode = ModeNormal
+        e.setStatusMsg("")
+    case tcell.KeyEnter:
+        e.codeActions = nil
+        e.mode = ModeNormal
+        e.runCodeAction(m.client, m.actions[m.popup.Selected])
+    }
+    return true
+}
+
+// runCodeAction applies a code action. It may be a bare command, carry
+// an edit, a command or both, or need resolving first.
+func (e *Editor) runCodeAction(c *lspClient, raw json.RawMessage) {
+    var action struct {
+        Title   string            `json:"title"`
+        Edit    *lspWorkspaceEdit `json:"edit"`
+        Command json.RawMessage   `json:"command"`
+        Data    json.RawMessage   `json:"data"`
+    }
+    if err := json.Unmarshal(raw, &action); err != nil {
+        e.setStatusMsg(fmt.Sprintf("Bad code action: %v", err))
+        return
+    }
+
+    var name string
+    if json.Unmarshal(action.Command, &name) == nil {
+        e.executeCommand(c, action.Title, raw)
+        return
+    }
+    if action.Edit == nil && action.Command == nil {
+        var resolved json.RawMessage
+        go func() {
+            err := c.call("codeAction/resolve", raw, &resolved)
+            e.runOnMain(func() {
+                if err != nil || len(resolved) == 0 {
+                    e.setStatusMsg(fmt.Sprintf("Cannot resolve '%s': %v", action.Title, err))
+                    return
+                }
+                if string(resolved) != string(raw) {
+                    e.runCodeAction(c, resolved)
+                }
+            })
+        }()
+        return
+    }
+
+    if action.Edit != nil {
+        if _, err := e.applyWorkspaceEdit(c, *action.Edit); err != nil {
+            e.setStatusMsg(fmt.Sprintf("'%s' failed: %v", action.Title, err))
+            return
+        }
+        e.setStatusMsg(fmt.Sprintf("Applied '%s'", action.Title))
+    }
+    if action.Command != nil {
+        e.executeCommand(c, action.Title, action.Command)
+    }
+}
+
+// executeCommand runs a server command; its changes come back as
+// workspace/applyEdit requests.
+func (e *Editor) executeCommand(c *lspClient, title string, raw json.RawMessage) {
+    var cmd lspCommand
+    if err := json.Unmarshal(raw, &cmd); err != nil {
+        e.setStatusMsg(fmt.Sprintf("Bad command: %v", err))
+        return
+    }
+    params := map[string]interface{}{"command": cmd.Command, "arguments": cmd.Arguments}
+    go func() {
+        err := c.call("workspace/executeCommand", params, nil)
+        e.runOnMain(func() {
+            if err != nil {
+                e.setStatusMsg(fmt.Sprintf("'%s' failed: %v", title, err))
+                return
+            }
+            e.setStatusMsg(fmt.Sprintf("Applied '%s'", title))
+        })
+    }()
+}
+
+// lspComplete asks the server for completions at the cursor. It returns
+// false when there is no ready server, so word completion can be used.
+func (e *Editor) lspComplete(tab *Tab) bool {
+    c := e.languageServer(tab)
+    if c == nil || !c.isReady() {
+        return false
+    }
+    row, col := tab.cursor.Row, tab.cursor.Col
+    var result json.RawMessage
+    return e.lspRequest(tab, "textDocument/completion", func(c *lspClient) interface{} {
+        return positionParams(c, tab)
+    }, &result, func(c *lspClient) {
+        if e.tabManager.GetActiveTab() != tab || tab.cursor.Row != row || tab.cursor.Col != col {
+            e.setStatusMsg("")
+            return
+        }
+        items := decodeCompletions(result)
+        start, prefix := e.completionPrefix(tab)
+        e.completion = &completionState{popup: NewPopup(nil, 8), row: row, start: start, lspItems: items}
+        if len(filterCompletions(items, prefix)) == 0 {
+            e.completion = nil
+            e.setStatusMsg(fmt.Sprintf("No completions for '%s'", prefix))
+            return
+        }
+        e.completion.popup.SetItems(filterCompletions(items, prefix))
+        e.setStatusMsg("")
+    })
+}
+
+// decodeCompletions reads a CompletionList or a list of items, in the
+// order given by their sort text.
+func decodeCompletions(raw json.RawMessage) []PopupItem {
+    type item struct {
+        Label      string `json:"label"`
+        Detail     string `json:"detail"`
+        SortText   string `json:"sortText"`
+        FilterText string `json:"filterText"`
+        InsertText string `json:"insertText"`
+        TextEdit   *struct {
+            NewText string `json:"newText"`
+        } `json:"textEdit"`
+    }
+    var list struct {
+        Items []item `json:"items"`
+    }
+    if json.Unmarshal(raw, &list.Items) != nil {
+        json.Unmarshal(raw, &list)
+    }
+    sort.SliceStable(list.Items, func(i, j int) bool {
+        a, b := list.Items[i], list.Items[j]
+        if a.SortText == "" {
+            a.SortText = a.Label
+        }
+        if b.SortText == "" {
+            b.SortText = b.Label
+        }
+        return a.SortText < b.SortText
+    })
+
+    items := make([]PopupItem, 0, len(list.Items))
+    for _, it := range list.Items {
+        insert := it.InsertText
+        if it.TextEdit != nil {
+            insert = it.TextEdit.NewText
+        }
+        if insert == "" {
+            insert = it.Label
+        }
+        filter := it.FilterText
+        if filter == "" {
+            filter = insert
+        }
+        items = append(items, PopupItem{Label: it.Label, Detail: it.Detail, Insert: insert, filter: filter})
+    }
+    return items
+}
+
+// filterCompletions keeps the server completions that start with prefix,
+// ignoring case.
+func filterCompletions(items []PopupItem, prefix string) []PopupItem {
+    lower := strings.ToLower(prefix)
+    var result []PopupItem
+    for _, item := range items {
+        if strings.HasPrefix(strings.ToLower(item.filter), lower) {
+            result = append(result, item)
+        }
+        if len(result) == maxCompletions {
+            break
+        }
+    }
+    return result
+}
@@ -0,0 +1,275 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const defaultMacroName = "last"
+
+type MacroKey struct {
+    Key  tcell.Key     `json:"key"`
+    Rune rune          `json:"rune,omitempty"`
+    Mod  tcell.ModMask `json:"mod,omitempty"`
+}
+
+func macrosPath() string {
+    return filepath.Join(configDir(), "macros.json")
+}
+
+func loadMacros() map[string][]MacroKey {
+    macros := make(map[string][]MacroKey)
+    data, err := os.ReadFile(macrosPath())
+    if err != nil {
+        return macros
+    }
+    if err := json.Unmarshal(data, &macros); err != nil {
+        return make(map[string][]MacroKey)
+    }
+    return macros
+}
+
+func saveMacros(macros map[string][]MacroKey) error {
+    data, err := json.MarshalIndent(macros, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(configDir(), 0755); err != nil {
+        return err
+    }
+    return os.WriteFile(macrosPath(), data, 0644)
+}
+
+// handleMacroKey deals with the recording and playback keys and records
+// every other key while a recording is running. It reports whether the
+// event was consumed.
+func (e *Editor) handleMacroKey(ev *tcell.EventKey) bool {
+    if e.playingMacro {
+        return false
+    }
+
+    switch ev.Key() {
+    case tcell.KeyF7:
+        if !e.recording && e.mode != ModeNormal {
+            return false
+        }
+        e.toggleMacroRecording()
+        return true
+    case tcell.KeyF8:
+        if e.mode != ModeNormal || e.recording {
+            return false
+        }
+        e.playMacroKey()
+        return true
+    }
+
+    if e.recording {
+        e.recordBuffer = append(e.recordBuffer, MacroKey{Key: ev.Key(), Rune: ev.Rune(), Mod: ev.Modifiers()})
+    }
+    return false
+}
+
+func (e *Editor) toggleMacroRecording() {
+    if !e.recording {
+        e.recording = true
+        e.recordBuffer = nil
+        e.setStatusMsg("Recording macro... (F7 to stop)")
+        return
+    }
+
+    e.recording = false
+    e.abortReplace()
+    if len(e.recordBuffer) == 0 {
+        e.setStatusMsg("Macro recording cancelled (no keys recorded)")
+        return
+    }
+    e.mode = ModeMacroName
+    e.inputBuffer = ""
+    e.setStatusMsg(fmt.Sprintf("Macro name (Enter = %s): ", defaultMacroName))
+}
+
+func (e *Editor) handleMacroNameMode(ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.recordBuffer = nil
+        e.setStatusMsg("Macro discarded")
+    case tcell.KeyEnter:
+        e.mode = ModeNormal
+        name := strings.TrimSpace(e.inputBuffer)
+        if name == "" {
+            name = defaultMacroName
+        }
+        e.macros[name] = e.recordBuffer
+        e.lastMacro = name
+        e.recordBuffer = nil
+        if err := saveMacros(e.macros); err != nil {
+            e.setStatusMsg(fmt.Sprintf("Macro '%s' recorded but not saved: %v", name, err))
+        } else {
+            e.setStatusMsg(fmt.Sprintf("Macro '%s' recorded (%d keys). F8 to play", name, len(e.macros[name])))
+        }
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg(fmt.Sprintf("Macro name (Enter = %s): %s", defaultMacroName, e.inputBuffer))
+    case tcell.KeyRune:
+        if ev.Rune() != ' ' {
+            e.inputBuffer += string(ev.Rune())
+        }
+        e.setStatusMsg(fmt.Sprintf("Macro name (Enter = %s): %s", defaultMacroName, e.inputBuffer))
+    }
+    return true
+}
+
+// playMacroKey plays the last macro once, or on every selected line when
+// there is a selection.
+func (e *Editor) playMacroKey() {
+    name := e.lastMacro
+    if name == "" {
+        name = defaultMacroName
+    }
+    tab := e.tabManager.GetActiveTab()
+    if tab != nil && tab.selActive {
+        e.playMacroOnLines(name)
+    } else {
+        e.playMacro(name, 1)
+    }
+}
+
+// beginMacroBatch makes everything a macro does to any buffer undo as
+// one step. The returned function ends the batch.
+func (e *Editor) beginMacroBatch() func() {
+    e.playingMacro = true
+    e.macroBatches = make(map[*Buffer]*Tab)
+    return func() {
+        for b, t := range e.macroBatches {
+            b.EndBatch(t.cursor.Row, t.cursor.Col)
+        }
+        e.macroBatches = nil
+        e.playingMacro = false
+    }
+}
+
+// batchMacroTabs starts the macro batch on buffers that do not have one
+// yet, including those the macro opened itself.
+func (e *Editor) batchMacroTabs() {
+    if e.macroBatches == nil {
+        return
+    }
+    for _, t := range e.tabManager.tabs {
+        if _, ok := e.macroBatches[t.buffer]; !ok {
+            t.buffer.BeginBatch()
+            e.macroBatches[t.buffer] = t
+        }
+    }
+}
+
+// runMacro feeds the keys through handleKey. It stops early and returns
+// false when a step fails, e.g. a search finds no further match or the
+// cursor cannot move any more.
+func (e *Editor) runMacro(keys []MacroKey) bool {
+    for _, k := range keys {
+        e.actionFailed = false
+        e.batchMacroTabs()
+
+        tab := e.tabManager.GetActiveTab()
+        var before Cursor
+        if tab != nil {
+            before = *tab.cursor
+        }
+
+        if !e.handleKey(tcell.NewEventKey(k.Key, k.Rune, k.Mod)) {
+            return false
+        }
+
+        if e.actionFailed {
+            return false
+        }
+        if e.mode == ModeNormal && isMovementKey(k.Key) && tab != nil && tab == e.tabManager.GetActiveTab() && *tab.cursor == before {
+            return false
+        }
+    }
+    return true
+}
+
+func (e *Editor) playMacro(name string, count int) {
+    keys, ok := e.macros[name]
+    if !ok {
+        e.setStatusMsg(fmt.Sprintf("No macro named '%s'", name))
+        return
+    }
+    e.lastMacro = name
+
+    end := e.beginMacroBatch()
+    done := 0
+    for done < count && e.runMacro(keys) {
+        done++
+    }
+    e.abortReplace()
+    end()
+    e.mode = ModeNormal
+
+    if done < count {
+        e.setStatusMsg(fmt.Sprintf("Macro '%s' stopped after %d of %d run(s)", name, done, count))
+    } else {
+        e.setStatusMsg(fmt.Sprintf("Macro '%s' played %d time(s)", name, done))
+    }
+}
+
+func (e *Editor) playMacroOnLines(name string) {
+    keys, ok := e.macros[name]
+    if !ok {
+        e.setStatusMsg(fmt.Sprintf("No macro named '%s'", name))
+        return
+    }
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil {
+        return
+    }
+    e.lastMacro = name
+
+    start, stop := tab.selectedLines()
+    total := stop - start
+    tab.clearSelection()
+
+    end := e.beginMacroBatch()
+    done := 0
+    for row := start; row < stop && row < tab.buffer.LineCount(); row++ {
+        count := tab.buffer.LineCount()
+        tab.cursor.Row = row
+        tab.cursor.Col = 0
+        ok := e.runMacro(keys)
+        e.abortReplace()
+        e.mode = ModeNormal
+        if !ok || e.tabManager.GetActiveTab() != tab {
+            break
+        }
+        done++
+        delta := tab.buffer.LineCount() - count
+        row += delta
+        stop += delta
+    }
+    end()
+
+    e.setStatusMsg(fmt.Sprintf("Macro '%s' applied to %d of %d line(s)", name, done, total))
+}
+
+func (e *Editor) macroNames() []string {
+    names := make([]string, 0, len(e.macros))
+    for name := range e.macros {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
@@ -14,6 +14,7 @@ import (
     "strings"
     "sync"
     "time"
+    "unicode/utf8"
 
     "github.com/gdamore/tcell/v2"
 )
@@ -40,6 +41,47 @@ type Editor struct {
     aiInProgress   bool
     aiCancel       chan bool
     streamEnabled  bool
+    pasting        bool
+    pasteBuffer    strings.Builder
+    config         *Config
+    languages      []*Language
+    bracketMarks   []Cursor
+    macros         map[string][]MacroKey
+    recording      bool
+    recordBuffer   []MacroKey
+    lastMacro      string
+    playingMacro   bool
+    macroBatches   map[*Buffer]*Tab
+    actionFailed   bool
+    snippets       map[string]map[string]*Snippet
+    completion     *completionState
+    autoWrap       bool
+    softWrap       bool
+    searchOpts     SearchOptions
+    replace        *replaceSession
+    searchOrigin   Cursor
+    searchMatches  []searchMatch
+    searchCurrent  int
+    searchHistory  []string
+    historyIndex   int
+    historyDraft   string
+    grep           *grepSearch
+    projectReplace *projectReplace
+    replaceBackups []replaceBackup
+    finder         *fileFinder
+    recentFiles    []string
+    showOutline    bool
+    outline        outlineCache
+    bracketCache   bracketCache
+    symbolPicker   *symbolPicker
+    lspClients     map[string]*lspClient
+    lspFailed      map[string]bool
+    diagnostics    map[string][]diagnostic
+    diagMarks      []diagnostic
+    hover          *Popup
+    codeActions    *codeActionMenu
+    mainMu         sync.Mutex
+    mainQueue      []func()
 }
 
 type EditorMode int
@@ -50,9 +92,22 @@ const (
     ModeGoto
     ModeLLM
     ModeFilename
+    ModeCommand
+    ModeMacroName
+    ModeReplace
+    ModeReplaceWith
+    ModeReplaceConfirm
+    ModeGrep
+    ModeGrepResults
+    ModeProjectReplaceWith
+    ModeProjectReplace
+    ModeFinder
+    ModeSymbol
+    ModeRename
+    ModeCodeAction
 )
 
-func NewEditor(filenames []string, ollamaURL, model string, streamEnabled bool) (*Editor, error) {
+func NewEditor(filenames []string, ollamaURL, model string, streamEnabled bool, config *Config) (*Editor, error) {
     screen, err := tcell.NewScreen()
     if err != nil {
         return nil, fmt.Errorf("failed to create screen: %w", err)
@@ -100,6 +155,14 @@ func NewEditor(filenames []string, ollamaURL, model string, streamEnabled bool)
         aiInProgress:  false,
         aiCancel:      make(chan bool, 1),
         streamEnabled: streamEnabled,
+        config:        config,
+        languages:     mergeLanguages(builtinLanguages(), config.Languages),
+        macros:        loadMacros(),
+        snippets:      loadSnippets(),
+        searchHistory: loadSearchHistory(),
+        recentFiles:   loadRecentFiles(),
+        autoWrap:      config.AutoWrap,
+        softWrap:      config.SoftWrap,
     }, nil
 }
 
@@ -133,6 +196,21 @@ func (e *Editor) appendLLMResponse(chunk string) {
     e.llmMutex.Unlock()
 }
 
+func (e *Editor) languageFor(tab *Tab) *Language {
+    if tab == nil || tab.buffer == nil {
+        return plainText
+    }
+    lang := detectLanguage(e.languages, tab.buffer.filename)
+    if ec := tab.buffer.editorConfig; ec != nil {
+        if unit := ec.indentUnit(lang.IndentUnit); unit != lang.IndentUnit {
+            override := *lang
+            override.IndentUnit = unit
+            return &override
+        }
+    }
+    return lang
+}
+
 func (e *Editor) checkOllamaSetup() error {
     if !e.llmClient.IsAvailable() {
         return fmt.Errorf("Ollama not running. Start with: ollama serve")
@@ -151,7 +229,9 @@ func (e *Editor) Run() error {
     }
 
     defer e.screen.Fini()
+    defer e.stopLanguageServers()
 
+    e.syncDocuments()
     e.render()
 
     for {
@@ -163,12 +243,17 @@ func (e *Editor) Run() error {
         if !e.handleEvent(ev) {
             return nil
         }
+        e.syncDocuments()
 
-        e.render()
+        if !e.pasting {
+            e.render()
+        }
     }
 }
 
 func (e *Editor) handleEvent(ev tcell.Event) bool {
+    defer e.syncFolds()
+
     switch ev := ev.(type) {
     case *tcell.EventResize:
         e.width, e.height = ev.Size()
@@ -179,7 +264,37 @@ func (e *Editor) handleEvent(ev tcell.Event) bool {
         e.screen.Sync()
         return true
 
+    case *tcell.EventInterrupt:
+        e.runQueued()
+        return true
+
+    case *tcell.EventPaste:
+        if ev.Start() {
+            e.pasting = true
+            e.pasteBuffer.Reset()
+        } else {
+            e.pasting = false
+            e.finishPaste()
+        }
+        return true
+
     case *tcell.EventKey:
+        if e.pasting {
+            e.collectPaste(ev)
+            return true
+        }
+
+        if e.handleMacroKey(ev) {
+            return true
+        }
+
+        if e.hover != nil {
+            e.hover = nil
+            if ev.Key() == tcell.KeyEscape {
+                return true
+            }
+        }
+
         if ev.Key() == tcell.KeyEscape {
             e.aiMutex.Lock()
             if e.aiInProgress {
@@ -218,6 +333,32 @@ func (e *Editor) handleKey(ev *tcell.EventKey) bool {
         return e.handleLLMMode(ev)
     case ModeFilename:
         return e.handleFilenameMode(ev)
+    case ModeCommand:
+        return e.handleCommandMode(ev)
+    case ModeMacroName:
+        return e.handleMacroNameMode(ev)
+    case ModeReplace:
+        return e.handleReplaceMode(ev)
+    case ModeReplaceWith:
+        return e.handleReplaceWithMode(ev)
+    case ModeReplaceConfirm:
+        return e.handleReplaceConfirmMode(ev)
+    case ModeGrep:
+        return e.handleGrepMode(ev)
+    case ModeGrepResults:
+        return e.handleGrepResultsMode(ev)
+    case ModeProjectReplaceWith:
+        return e.handleProjectReplaceWithMode(ev)
+    case ModeProjectReplace:
+        return e.handleProjectReplaceMode(ev)
+    case ModeFinder:
+        return e.handleFinderMode(ev)
+    case ModeSymbol:
+        return e.handleSymbolMode(ev)
+    case ModeRename:
+        return e.handleRenameMode(ev)
+    case ModeCodeAction:
+        return e.handleCodeActionMode(ev)
     default:
         return e.handleNormalMode(ev)
     }
@@ -229,8 +370,28 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         return true
     }
 
+    if tab.snippet != nil && e.handleSnippetKey(tab, ev) {
+        return true
+    }
+
+    if e.completion != nil && e.handleCompletionKey(tab, ev) {
+        return true
+    }
+
     mod := ev.Modifiers()
 
+    if ev.Key() != tcell.KeyRune && ev.Key() != tcell.KeyBackspace && ev.Key() != tcell.KeyBackspace2 {
+        tab.autoClosed = 0
+    }
+
+    if isMovementKey(ev.Key()) && mod&tcell.ModAlt == 0 {
+        if mod&tcell.ModShift != 0 {
+            tab.startSelection()
+        } else {
+            tab.clearSelection()
+        }
+    }
+
     switch ev.Key() {
     case tcell.KeyCtrlQ:
         e.aiMutex.Lock()
@@ -298,15 +459,66 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         }
 
     case tcell.KeyCtrlF:
-        e.mode = ModeFind
-        e.inputBuffer = ""
-        e.setStatusMsg("Find: ")
+        e.startFind(tab)
+
+    case tcell.KeyCtrlR:
+        e.startReplace()
+
+    case tcell.KeyCtrlP:
+        e.startFinder()
+
+    case tcell.KeyF12:
+        if mod&tcell.ModShift != 0 {
+            e.findReferences(tab)
+        } else {
+            e.gotoDefinition(tab)
+        }
+
+    case tcell.KeyF24:
+        e.findReferences(tab)
+
+    case tcell.KeyF2:
+        e.startRename(tab)
+
+    case tcell.KeyF4:
+        e.nextDiagnostic(tab, mod&tcell.ModShift != 0)
+
+    case tcell.KeyF16:
+        e.nextDiagnostic(tab, true)
+
+    case tcell.KeyF3:
+        e.findText(mod&tcell.ModShift != 0)
+
+    case tcell.KeyF15:
+        e.findText(true)
 
     case tcell.KeyCtrlG:
         e.mode = ModeGoto
         e.inputBuffer = ""
         e.setStatusMsg("Go to line: ")
 
+    case tcell.KeyCtrlE:
+        e.mode = ModeCommand
+        e.inputBuffer = ""
+        e.setStatusMsg("Command: ")
+
+    case tcell.KeyCtrlD:
+        e.duplicateLines(tab)
+
+    case tcell.KeyCtrlUnderscore:
+        e.toggleComment(tab)
+
+    case tcell.KeyCtrlB:
+        e.jumpToMatchingBracket(tab)
+
+    case tcell.KeyCtrlO:
+        e.expandSnippet(tab)
+
+    case tcell.KeyCtrlSpace:
+        if !e.lspComplete(tab) {
+            e.openCompletion(tab, true)
+        }
+
     case tcell.KeyCtrlL:
         if err := e.checkOllamaSetup(); err != nil {
             e.setStatusMsg(fmt.Sprintf("AI unavailable: %v", err))
@@ -323,26 +535,7 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
     case tcell.KeyCtrlK:
         response := e.getLLMResponse()
         if response != "" {
-            oldRow := tab.cursor.Row
-            oldCol := tab.cursor.Col
-            tab.buffer.InsertText(tab.cursor.Row, tab.cursor.Col, response)
-
-            lines := strings.Split(response, "\n")
-            if len(lines) > 1 {
-                tab.cursor.Row = oldRow + len(lines) - 1
-                if tab.cursor.Row < 0 {
-                    tab.cursor.Row = 0
-                }
-                if len(lines) > 0 {
-                    lastLine := lines[len(lines)-1]
-                    tab.cursor.Col = len(lastLine)
-                }
-            } else {
-                tab.cursor.Col = oldCol + len(response)
-            }
-
-            e.ensureCursorValid(tab)
-            tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+            e.insertTextAtCursor(tab, response)
             e.setStatusMsg("AI response inserted at cursor")
         } else {
             e.setStatusMsg("No AI response available. Use Ctrl+L to ask AI first")
@@ -354,14 +547,23 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         e.setStatusMsg("All text copied to system clipboard")
 
     case tcell.KeyCtrlC:
-        if tab.cursor.Row >= 0 && tab.cursor.Row < tab.buffer.LineCount() {
+        if text := tab.selectedText(); text != "" {
+            e.clipboard.Copy(text)
+            e.setStatusMsg("Selection copied to system clipboard")
+        } else if tab.cursor.Row >= 0 && tab.cursor.Row < tab.buffer.LineCount() {
             line := tab.buffer.GetLine(tab.cursor.Row)
             e.clipboard.Copy(line)
             e.setStatusMsg("Current line copied to system clipboard")
         }
 
     case tcell.KeyCtrlX:
-        if tab.cursor.Row >= 0 && tab.cursor.Row < tab.buffer.LineCount() {
+        if text := tab.selectedText(); text != "" {
+            e.clipboard.Copy(text)
+            tab.deleteSelection()
+            e.ensureCursorValid(tab)
+            tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+            e.setStatusMsg("Selection cut to system clipboard")
+        } else if tab.cursor.Row >= 0 && tab.cursor.Row < tab.buffer.LineCount() {
             line := tab.buffer.GetLine(tab.cursor.Row)
             e.clipboard.Copy(line)
             tab.buffer.DeleteLine(tab.cursor.Row)
@@ -377,32 +579,14 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
     case tcell.KeyCtrlV:
         text, err := e.clipboard.Paste()
         if err == nil && text != "" {
-            oldRow := tab.cursor.Row
-            oldCol := tab.cursor.Col
-            tab.buffer.InsertText(tab.cursor.Row, tab.cursor.Col, text)
-
-            lines := strings.Split(text, "\n")
-            if len(lines) > 1 {
-                tab.cursor.Row = oldRow + len(lines) - 1
-                if tab.cursor.Row < 0 {
-                    tab.cursor.Row = 0
-                }
-                if len(lines) > 0 {
-                    lastLine := lines[len(lines)-1]
-                    tab.cursor.Col = len(lastLine)
-                }
-            } else {
-                tab.cursor.Col = oldCol + len(text)
-            }
-
-            e.ensureCursorValid(tab)
-            tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+            e.insertTextAtCursor(tab, text)
             e.setStatusMsg("System clipboard content pasted")
         } else {
             e.setStatusMsg("Clipboard is empty or unavailable")
         }
 
     case tcell.KeyCtrlZ:
+        tab.clearSelection()
         if row, col, ok := tab.buffer.Undo(); ok {
             tab.cursor.Row = row
             tab.cursor.Col = col
@@ -413,6 +597,7 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         }
 
     case tcell.KeyCtrlY:
+        tab.clearSelection()
         if row, col, ok := tab.buffer.Redo(); ok {
             tab.cursor.Row = row
             tab.cursor.Col = col
@@ -423,20 +608,29 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         }
 
     case tcell.KeyUp:
-        if tab.cursor.Row > 0 {
-            tab.cursor.Row--
-            e.ensureCursorValid(tab)
+        if mod&tcell.ModAlt != 0 {
+            e.moveLines(tab, -1)
+        } else if mod&tcell.ModCtrl != 0 {
+            e.paragraphUp(tab)
+        } else {
+            e.moveScreenRow(tab, -1)
         }
 
     case tcell.KeyDown:
-        if tab.cursor.Row < tab.buffer.LineCount()-1 {
-            tab.cursor.Row++
-            e.ensureCursorValid(tab)
+        if mod&tcell.ModAlt != 0 {
+            e.moveLines(tab, 1)
+        } else if mod&tcell.ModCtrl != 0 {
+            e.paragraphDown(tab)
+        } else {
+            e.moveScreenRow(tab, 1)
         }
 
     case tcell.KeyLeft:
-        if tab.cursor.Col > 0 {
-            tab.cursor.Col--
+        if mod&(tcell.ModCtrl|tcell.ModAlt) != 0 {
+            e.wordLeft(tab)
+        } else if tab.cursor.Col > 0 {
+            _, size := utf8.DecodeLastRuneInString(tab.buffer.GetLine(tab.cursor.Row)[:tab.cursor.Col])
+            tab.cursor.Col -= size
         } else if tab.cursor.Row > 0 {
             tab.cursor.Row--
             tab.cursor.Col = len(tab.buffer.GetLine(tab.cursor.Row))
@@ -444,8 +638,11 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
 
     case tcell.KeyRight:
         lineLen := len(tab.buffer.GetLine(tab.cursor.Row))
-        if tab.cursor.Col < lineLen {
-            tab.cursor.Col++
+        if mod&(tcell.ModCtrl|tcell.ModAlt) != 0 {
+            e.wordRight(tab)
+        } else if tab.cursor.Col < lineLen {
+            _, size := utf8.DecodeRuneInString(tab.buffer.GetLine(tab.cursor.Row)[tab.cursor.Col:])
+            tab.cursor.Col += size
         } else if tab.cursor.Row < tab.buffer.LineCount()-1 {
             tab.cursor.Row++
             tab.cursor.Col = 0
@@ -492,20 +689,23 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         e.ensureCursorValid(tab)
 
     case tcell.KeyEnter:
-        tab.buffer.InsertNewline(tab.cursor.Row, tab.cursor.Col)
-        tab.cursor.Row++
-        if tab.cursor.Row < 0 {
-            tab.cursor.Row = 0
-        }
-        tab.cursor.Col = 0
+        tab.deleteSelection()
+        e.smartNewline(tab)
         e.ensureCursorValid(tab)
         tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
         e.quitAttempts = 0
 
     case tcell.KeyBackspace, tcell.KeyBackspace2:
-        if tab.cursor.Col > 0 {
+        if tab.deleteSelection() {
+            // removed the selection
+        } else if mod&(tcell.ModCtrl|tcell.ModAlt) != 0 {
+            e.deleteWordBackward(tab)
+        } else if e.deletePair(tab) || e.smartBackspace(tab) {
+            // removed an empty pair or one indentation level
+        } else if tab.cursor.Col > 0 {
+            _, size := utf8.DecodeLastRuneInString(tab.buffer.GetLine(tab.cursor.Row)[:tab.cursor.Col])
             tab.buffer.DeleteChar(tab.cursor.Row, tab.cursor.Col)
-            tab.cursor.Col--
+            tab.cursor.Col -= size
         } else if tab.cursor.Row > 0 {
             prevLineLen := len(tab.buffer.GetLine(tab.cursor.Row - 1))
             tab.buffer.DeleteChar(tab.cursor.Row, tab.cursor.Col)
@@ -515,10 +715,15 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         e.ensureCursorValid(tab)
         tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
         e.quitAttempts = 0
+        e.refreshCompletion(tab, false)
 
     case tcell.KeyDelete:
         lineLen := len(tab.buffer.GetLine(tab.cursor.Row))
-        if tab.cursor.Col < lineLen {
+        if tab.deleteSelection() {
+            // removed the selection
+        } else if mod&tcell.ModCtrl != 0 {
+            e.deleteWordForward(tab)
+        } else if tab.cursor.Col < lineLen {
             tab.buffer.DeleteCharForward(tab.cursor.Row, tab.cursor.Col)
         } else if tab.cursor.Row < tab.buffer.LineCount()-1 {
             nextLine := tab.buffer.GetLine(tab.cursor.Row + 1)
@@ -530,43 +735,72 @@ func (e *Editor) handleNormalMode(ev *tcell.EventKey) bool {
         e.quitAttempts = 0
 
     case tcell.KeyRune:
-        if ev.Rune() == '\t' {
-            for i := 0; i < 4; i++ {
-                tab.buffer.InsertChar(tab.cursor.Row, tab.cursor.Col, ' ')
-                tab.cursor.Col++
-            }
-        } else {
+        if mod&tcell.ModAlt != 0 {
+            e.handleAltRune(tab, ev.Rune())
+            return true
+        }
+
+        tab.deleteSelection()
+        if !e.autoPair(tab, ev.Rune()) {
+            e.electricCloser(tab, ev.Rune())
             tab.buffer.InsertChar(tab.cursor.Row, tab.cursor.Col, ev.Rune())
-            tab.cursor.Col++
+            tab.cursor.Col += utf8.RuneLen(ev.Rune())
+            if e.autoWrap && ev.Rune() != ' ' {
+                e.wrapWhileTyping(tab)
+            }
         }
         e.ensureCursorValid(tab)
         tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
         e.quitAttempts = 0
+        e.refreshCompletion(tab, true)
     }
 
     return true
 }
 
-
-func (e *Editor) handleFindMode(ev *tcell.EventKey) bool {
-    switch ev.Key() {
-    case tcell.KeyEscape:
-        e.mode = ModeNormal
-        e.setStatusMsg("Search cancelled")
-    case tcell.KeyEnter:
-        e.findQuery = e.inputBuffer
-        e.findText()
-        e.mode = ModeNormal
-    case tcell.KeyBackspace, tcell.KeyBackspace2:
-        if len(e.inputBuffer) > 0 {
-            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
-        }
-        e.setStatusMsg("Find: " + e.inputBuffer)
-    case tcell.KeyRune:
-        e.inputBuffer += string(ev.Rune())
-        e.setStatusMsg("Find: " + e.inputBuffer)
+func (e *Editor) handleAltRune(tab *Tab, r rune) {
+    switch r {
+    case 'd':
+        e.deleteLines(tab)
+    case 'j':
+        e.joinLines(tab)
+    case 's':
+        e.sortLines(tab, SortLexical, false)
+    case 'S':
+        e.sortLines(tab, SortLexical, true)
+    case 'r':
+        e.reverseLines(tab)
+    case 'u':
+        e.uniqueLines(tab)
+    case 'x':
+        e.shuffleLines(tab)
+    case 'q':
+        e.reflow(tab, 0)
+    case 'z':
+        e.toggleSoftWrap()
+    case 'f':
+        e.toggleFold(tab)
+    case '=', '+':
+        e.increment(tab, 1, "")
+    case '-':
+        e.increment(tab, -1, "")
+    case 'n':
+        e.findText(false)
+    case 'p':
+        e.findText(true)
+    case 'g':
+        e.startGrepPrompt()
+    case 'o':
+        e.toggleOutline(tab)
+    case 't':
+        e.startSymbolPicker(tab)
+    case 'h':
+        e.hoverInfo(tab)
+    case 'a':
+        e.codeAction(tab)
+    case 'F':
+        e.formatDocument(tab)
     }
-    return true
 }
 
 func (e *Editor) handleGotoMode(ev *tcell.EventKey) bool {
@@ -588,6 +822,7 @@ func (e *Editor) handleGotoMode(ev *tcell.EventKey) bool {
             e.ensureCursorValid(tab)
             e.setStatusMsg(fmt.Sprintf("Jumped to line %d", lineNum))
         } else {
+            e.actionFailed = true
             e.setStatusMsg("Invalid line number")
         }
         e.mode = ModeNormal
@@ -650,6 +885,7 @@ func (e *Editor) handleFilenameMode(ev *tcell.EventKey) bool {
         e.setStatusMsg("Save cancelled")
     case tcell.KeyEnter:
         tab.buffer.filename = e.inputBuffer
+        tab.buffer.editorConfig = findEditorConfig(e.inputBuffer)
         e.saveFile()
         e.mode = ModeNormal
     case tcell.KeyBackspace, tcell.KeyBackspace2:
@@ -677,68 +913,47 @@ func (e *Editor) saveFile() {
         return
     }
 
+    var formatErr error
+    if e.config.FormatOnSave && hasFormatter(e.languageFor(tab)) {
+        formatErr = e.formatBuffer(tab)
+    }
+    if ec := tab.buffer.editorConfig; ec != nil && ec.TrimTrailingWhitespace {
+        e.trimTrailingWhitespace(tab)
+    }
+
     if err := tab.buffer.Save(); err != nil {
         e.setStatusMsg(fmt.Sprintf("Save failed: %v", err))
     } else {
         basename := filepath.Base(tab.buffer.filename)
-        e.setStatusMsg(fmt.Sprintf("Saved '%s' (%d lines)", basename, tab.buffer.LineCount()))
+        msg := fmt.Sprintf("Saved '%s' (%d lines)", basename, tab.buffer.LineCount())
+        if formatErr != nil {
+            msg += fmt.Sprintf(" - format failed: %v", formatErr)
+        }
+        e.setStatusMsg(msg)
+        e.notifySaved(tab)
     }
 }
 
-func (e *Editor) findText() {
-    tab := e.tabManager.GetActiveTab()
-    if tab == nil || tab.buffer == nil || tab.cursor == nil {
-        return
-    }
-
-    if e.findQuery == "" {
-        e.setStatusMsg("No search query entered")
-        return
+// trimTrailingWhitespace removes trailing spaces and tabs from every
+// line as one undo step, keeping the cursor inside its line.
+func (e *Editor) trimTrailingWhitespace(tab *Tab) {
+    lines := tab.buffer.GetLines(0, tab.buffer.LineCount())
+    changed := false
+    for i, line := range lines {
+        if trimmed := strings.TrimRight(line, " \t"); trimmed != line {
+            lines[i] = trimmed
+            changed = true
+        }
     }
-
-    totalLines := tab.buffer.LineCount()
-    if totalLines == 0 {
-        e.setStatusMsg("Buffer is empty")
+    if !changed {
         return
     }
-
-    startRow := tab.cursor.Row
-    startCol := tab.cursor.Col + 1
-
-    if startRow < 0 {
-        startRow = 0
-    }
-    if startRow >= totalLines {
-        startRow = totalLines - 1
-    }
-
-    for i := 0; i < totalLines; i++ {
-        row := (startRow + i) % totalLines
-        line := tab.buffer.GetLine(row)
-
-        searchFrom := 0
-        if row == startRow && i == 0 {
-            searchFrom = startCol
-        }
-
-        if searchFrom >= len(line) {
-            continue
-        }
-
-        lowerLine := strings.ToLower(line[searchFrom:])
-        lowerQuery := strings.ToLower(e.findQuery)
-        idx := strings.Index(lowerLine, lowerQuery)
-
-        if idx != -1 {
-            tab.cursor.Row = row
-            tab.cursor.Col = searchFrom + idx
-            e.ensureCursorValid(tab)
-            e.setStatusMsg(fmt.Sprintf("Found '%s' at line %d, column %d", e.findQuery, row+1, tab.cursor.Col+1))
-            return
-        }
+    tab.buffer.ReplaceLines(0, tab.buffer.LineCount(), lines)
+    if tab.selActive {
+        tab.selAnchor.Col = clampCol(tab.buffer.GetLine(tab.selAnchor.Row), tab.selAnchor.Col)
     }
-
-    e.setStatusMsg(fmt.Sprintf("'%s' not found in document", e.findQuery))
+    e.ensureCursorValid(tab)
+    tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
 }
 
 func (e *Editor) askLLMAsync() {
@@ -950,6 +1165,67 @@ func (e *Editor) askLLMStream() {
     }()
 }
 
+func (e *Editor) collectPaste(ev *tcell.EventKey) {
+    switch ev.Key() {
+    case tcell.KeyRune:
+        e.pasteBuffer.WriteRune(ev.Rune())
+    case tcell.KeyEnter, tcell.KeyLF:
+        e.pasteBuffer.WriteByte('\n')
+    case tcell.KeyTab:
+        e.pasteBuffer.WriteByte('\t')
+    }
+}
+
+func (e *Editor) finishPaste() {
+    text := e.pasteBuffer.String()
+    e.pasteBuffer.Reset()
+    if text == "" {
+        return
+    }
+
+    text = strings.ReplaceAll(text, "\r\n", "\n")
+    text = strings.ReplaceAll(text, "\r", "\n")
+
+    if e.mode != ModeNormal {
+        // Prompts are single-line: feed the first line through the
+        // active mode handler as if it had been typed.
+        if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+            text = text[:idx]
+        }
+        for _, r := range text {
+            e.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+        }
+        return
+    }
+
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil {
+        return
+    }
+
+    e.insertTextAtCursor(tab, text)
+    e.quitAttempts = 0
+    e.setStatusMsg(fmt.Sprintf("Pasted %d lines", strings.Count(text, "\n")+1))
+}
+
+func (e *Editor) insertTextAtCursor(tab *Tab, text string) {
+    tab.deleteSelection()
+    oldRow := tab.cursor.Row
+    oldCol := tab.cursor.Col
+    tab.buffer.InsertText(tab.cursor.Row, tab.cursor.Col, text)
+
+    lines := strings.Split(text, "\n")
+    if len(lines) > 1 {
+        tab.cursor.Row = oldRow + len(lines) - 1
+        tab.cursor.Col = len(lines[len(lines)-1])
+    } else {
+        tab.cursor.Col = oldCol + len(text)
+    }
+
+    e.ensureCursorValid(tab)
+    tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+}
+
 func (e *Editor) ensureCursorValid(tab *Tab) {
     if tab == nil || tab.cursor == nil || tab.buffer == nil {
         return
@@ -989,42 +1265,74 @@ func (e *Editor) render() {
 
     e.ensureCursorValid(tab)
 
-    if tab.cursor.Row < tab.offsetRow {
-        tab.offsetRow = tab.cursor.Row
+    tab.revealRow(tab.cursor.Row)
+    e.scrollVertical(tab)
+    e.scrollHorizontal(tab)
+
+    e.renderTabBar()
+
+    if e.mode == ModeSymbol && e.symbolPicker != nil {
+        e.renderSymbolPicker()
+        e.renderStatusBar()
+        e.screen.HideCursor()
+        e.screen.Show()
+        return
     }
-    if tab.cursor.Row >= tab.offsetRow+e.height && e.height > 0 {
-        tab.offsetRow = tab.cursor.Row - e.height + 1
+
+    if e.mode == ModeFinder && e.finder != nil {
+        e.renderFinder()
+        e.renderStatusBar()
+        e.screen.HideCursor()
+        e.screen.Show()
+        return
     }
-    if tab.offsetRow < 0 {
-        tab.offsetRow = 0
+
+    if e.mode == ModeProjectReplace && e.projectReplace != nil {
+        e.renderProjectReplace()
+        e.renderStatusBar()
+        e.screen.HideCursor()
+        e.screen.Show()
+        return
     }
 
-    e.renderTabBar()
+    if (e.mode == ModeGrepResults || e.mode == ModeProjectReplaceWith) && e.grep != nil {
+        e.renderGrepResults()
+        e.renderStatusBar()
+        e.screen.HideCursor()
+        e.screen.Show()
+        return
+    }
 
+    e.bracketMarks = e.bracketMarks[:0]
+    if from, to, ok := e.matchBracket(tab, tab.cursor.Row, tab.cursor.Col); ok {
+        e.bracketMarks = append(e.bracketMarks, from, to)
+    }
+
+    e.diagMarks = e.diagnosticsFor(tab)
+
+    rows := e.visibleRows(tab)
     for y := 0; y < e.height; y++ {
-        row := y + tab.offsetRow
         screenY := y + 1
-        
-        if screenY < 0 || screenY >= e.height+3 {
-            continue
-        }
-        
-        if row >= tab.buffer.LineCount() {
+
+        if y >= len(rows) {
             e.drawString(0, screenY, "~", tcell.StyleDefault.Foreground(tcell.ColorBlue))
             continue
         }
 
-        line := tab.buffer.GetLine(row)
-        e.drawString(0, screenY, line, tcell.StyleDefault)
+        e.drawScreenRow(tab, rows[y], screenY)
+        if !rows[y].cont {
+            e.drawGutter(rows[y].row, screenY)
+        }
     }
 
+    e.renderOutline(tab)
     e.renderStatusBar()
 
-    screenY := tab.cursor.Row - tab.offsetRow + 1
-    screenX := tab.cursor.Col
+    screenX, screenY := e.cursorScreenPos(tab, rows)
+    screenY++
 
-    if screenX >= e.width {
-        screenX = e.width - 1
+    if screenX >= e.textWidth() {
+        screenX = e.textWidth() - 1
     }
     if screenX < 0 {
         screenX = 0
@@ -1036,6 +1344,15 @@ func (e *Editor) render() {
         screenY = 1
     }
 
+    screenX += e.gutterWidth()
+    e.renderCompletion(tab, screenX, screenY)
+    if e.hover != nil {
+        e.hover.Draw(e.screen, screenX, screenY, 1, e.height, e.width)
+    }
+    if e.codeActions != nil {
+        e.codeActions.popup.Draw(e.screen, screenX, screenY, 1, e.height, e.width)
+    }
+
     e.screen.ShowCursor(screenX, screenY)
     e.screen.Show()
 }
@@ -1156,6 +1473,9 @@ func (e *Editor) renderStatusBar() {
     info := fmt.Sprintf("%s%s | Ln %d/%d | Col %d | Tab %d/%d",
         filename, modMark, tab.cursor.Row+1, tab.buffer.LineCount(), tab.cursor.Col+1,
         e.tabManager.activeTab+1, e.tabManager.GetTabCount())
+    if e.recording {
+        info += " | REC"
+    }
 
     if len(info) > e.width && e.width > 0 {
         info = info[:e.width]
@@ -1166,6 +1486,29 @@ func (e *Editor) renderStatusBar() {
     }
 }
 
+func (e *Editor) cellStyle(tab *Tab, row, col int) tcell.Style {
+    if tab.isSelected(row, col) {
+        return tcell.StyleDefault.Reverse(true)
+    }
+    if e.isSearchHit(row, col) {
+        return tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+    }
+    if tab.snippet != nil && tab.snippet.isCurrentField(row, col) {
+        return tcell.StyleDefault.Underline(true).Foreground(tcell.ColorYellow)
+    }
+    for _, m := range e.bracketMarks {
+        if m.Row == row && m.Col == col {
+            return tcell.StyleDefault.Background(tcell.ColorTeal).Foreground(tcell.ColorWhite).Bold(true)
+        }
+    }
+    for _, d := range e.diagMarks {
+        if d.covers(row, col) {
+            return tcell.StyleDefault.Underline(true).Foreground(severityColor(d.severity))
+        }
+    }
+    return tcell.StyleDefault
+}
+
 func (e *Editor) drawString(x, y int, s string, style tcell.Style) {
     if e.screen == nil || y < 0 || y >= e.height+3 || x < 0 {
         return
@@ -1184,6 +1527,7 @@ func main() {
     ollamaURL := flag.String("ollama", "http://localhost:11434", "Ollama API URL")
     model := flag.String("model", "llama2", "LLM model to use")
     streamEnabled := flag.Bool("stream", false, "Enable streaming AI responses")
+    configPath := flag.String("config", defaultConfigPath(), "Path to config file")
     showVersion := flag.Bool("version", false, "Show version")
     showHelp := flag.Bool("help", false, "Show help")
 
@@ -1210,7 +1554,12 @@ func main() {
         filenames = append(filenames, filename)
     }
 
-    ed, err := NewEditor(filenames, *ollamaURL, *model, *streamEnabled)
+    config, err := LoadConfig(*configPath)
+    if err != nil {
+        log.Fatalf("Failed to load config: %v", err)
+    }
+
+    ed, err := NewEditor(filenames, *ollamaURL, *model, *streamEnabled, config)
     if err != nil {
         log.Fatalf("Failed to create editor: %v", err)
     }
@@ -1229,6 +1578,7 @@ func printHelp() {
     fmt.Println("  -ollama string    Ollama API URL (default: http://localhost:11434)")
     fmt.Println("  -model string     LLM model to use (default: llama2)")
     fmt.Println("  -stream           Enable streaming AI responses")
+    fmt.Println("  -config string    Path to config file (default: <user config dir>/goedit/config.json)")
     fmt.Println("  -version          Show version")
     fmt.Println("  -help             Show this help")
     fmt.Println("\nKeyboard Shortcuts:")
@@ -1237,21 +1587,74 @@ func printHelp() {
     fmt.Println("    Ctrl+Q         Quit editor")
     fmt.Println("    Ctrl+T         New tab")
     fmt.Println("    Ctrl+W         Close current tab")
+    fmt.Println("    Ctrl+P         Find a file by fuzzy name and open it (or switch to its tab)")
     fmt.Println("    Tab            Next tab")
     fmt.Println("    Shift+Tab      Previous tab")
     fmt.Println("\n  Editing:")
     fmt.Println("    Ctrl+A         Copy all text to system clipboard")
-    fmt.Println("    Ctrl+C         Copy current line to system clipboard")
-    fmt.Println("    Ctrl+X         Cut current line to system clipboard")
+    fmt.Println("    Ctrl+C         Copy selection or current line to system clipboard")
+    fmt.Println("    Ctrl+X         Cut selection or current line to system clipboard")
     fmt.Println("    Ctrl+V         Paste from system clipboard")
     fmt.Println("    Ctrl+Z         Undo")
     fmt.Println("    Ctrl+Y         Redo")
+    fmt.Println("    Shift+Arrows   Select text")
+    fmt.Println("    Ctrl+Backspace Delete previous word (also Alt+Backspace)")
+    fmt.Println("    Ctrl+Delete    Delete next word")
+    fmt.Println("\n  Lines (current line or selection):")
+    fmt.Println("    Alt+Up/Down    Move lines up/down")
+    fmt.Println("    Ctrl+D         Duplicate lines")
+    fmt.Println("    Alt+J          Join with next line")
+    fmt.Println("    Alt+D          Delete lines")
+    fmt.Println("    Alt+S          Sort lines (Alt+Shift+S ignores case)")
+    fmt.Println("    Alt+R          Reverse lines")
+    fmt.Println("    Alt+U          Remove duplicate lines")
+    fmt.Println("    Alt+X          Shuffle lines")
+    fmt.Println("    Ctrl+/         Toggle comment")
+    fmt.Println("    Alt+= / Alt+-  Increment/decrement number, date or boolean (a selection makes a sequence)")
+    fmt.Println("    Alt+Q          Reflow paragraph or selection (config: \"wrap_width\", default 80)")
+    fmt.Println("    Ctrl+E         'reflow [WIDTH]', 'auto-wrap' to wrap while typing")
+    fmt.Println("    Ctrl+E         'inc [N] [UNIT]', 'dec [N] [UNIT]' (UNIT: year, month, day, hour, minute, second)")
+    fmt.Println("\n  Brackets:")
+    fmt.Println("    Ctrl+B         Jump to matching bracket")
+    fmt.Println("    ( [ { \" '      Insert closing pair (config: \"auto_close\": false to disable)")
+    fmt.Println("    Ctrl+E         Command prompt (e.g. 'sort numeric -i', 'sort natural')")
     fmt.Println("\n  Navigation:")
-    fmt.Println("    Ctrl+F         Find text")
+    fmt.Println("    Ctrl+F         Find as you type; Enter accepts, Esc returns to the start")
+    fmt.Println("                   In the prompt Alt+R regex, Alt+C case, Alt+W word, Alt+M multiline,")
+    fmt.Println("                   Up/Down browse earlier searches")
+    fmt.Println("    F3 / Shift+F3  Find next/previous (also Alt+N / Alt+P)")
+    fmt.Println("    Alt+O          Show or hide the outline of a Go file")
+    fmt.Println("    Alt+T          Jump to a symbol of a Go file by fuzzy name")
+    fmt.Println("    Alt+Shift+F    Format the buffer")
+    fmt.Println("    F12            Go to definition (Shift+F12: references, language server)")
+    fmt.Println("    F2             Rename symbol in all files (language server)")
+    fmt.Println("    F4 / Shift+F4  Next/previous diagnostic")
+    fmt.Println("    Alt+H / Alt+A  Symbol documentation / code actions (language server)")
+    fmt.Println("    Alt+G          Search all files under the current directory (respects .gitignore)")
+    fmt.Println("                   In the results, r replaces in all files with a diff preview")
+    fmt.Println("    Ctrl+R         Find and replace in the selection or from the cursor; confirm with y/n/a/q")
+    fmt.Println("                   Regex replacements accept $1, ${name}, \\U \\L \\E \\u \\l, \\n and \\t")
     fmt.Println("    Ctrl+G         Go to line")
+    fmt.Println("    Ctrl+Left/Right Previous/next word")
+    fmt.Println("    Ctrl+Up/Down   Previous/next paragraph")
     fmt.Println("    Home/End       Line start/end")
     fmt.Println("    Ctrl+Home/End  File start/end")
     fmt.Println("    Page Up/Down   Scroll page")
+    fmt.Println("    Alt+Z          Toggle soft wrap (Up/Down then move by screen row)")
+    fmt.Println("                   Without soft wrap long lines scroll sideways (config: \"scroll_margin\")")
+    fmt.Println("\n  Folding:")
+    fmt.Println("    Alt+F          Fold/unfold the block at the cursor (indentation, braces or {{{ }}} markers)")
+    fmt.Println("    Ctrl+E         'fold', 'unfold', 'fold-all', 'unfold-all', 'fold-level N'")
+    fmt.Println("\n  Completion:")
+    fmt.Println("    Ctrl+Space     Complete the word before the cursor from all open tabs")
+    fmt.Println("    Up/Down Enter  Choose and accept, Esc to close")
+    fmt.Println("\n  Snippets:")
+    fmt.Println("    Ctrl+O         Expand the snippet trigger before the cursor")
+    fmt.Println("    Tab/Shift+Tab  Next/previous snippet field, Esc to finish")
+    fmt.Println("\n  Macros:")
+    fmt.Println("    F7             Start/stop recording a macro")
+    fmt.Println("    F8             Play last macro (on every selected line if there is a selection)")
+    fmt.Println("    Ctrl+E         'macro NAME [N]', 'macro-lines NAME', 'macros', 'macro-delete NAME'")
     fmt.Println("\n  AI Assistant:")
     fmt.Println("    Ctrl+L         Ask AI (with optional streaming)")
     fmt.Println("    Ctrl+K         Insert AI response at cursor")
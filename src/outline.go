@@ -0,0 +1,391 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "go/ast"
+    "go/parser"
+    "go/token"
+    "sort"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const (
+    maxOutlineWidth     = 32
+    minScreenForOutline = 60
+)
+
+// goSymbol is a top-level declaration of a Go file. Methods are named
+// Receiver.Method.
+type goSymbol struct {
+    name string
+    kind string
+    row  int
+    col  int
+}
+
+// outlineCache keeps the symbols of the last parsed buffer, so the
+// outline is only reparsed after the text changes.
+type outlineCache struct {
+    buffer  *Buffer
+    text    string
+    symbols []goSymbol
+    err     error
+}
+
+// symbolPicker is the state of the jump-to-symbol prompt.
+type symbolPicker struct {
+    query    string
+    matches  []goSymbol
+    selected int
+    top      int
+}
+
+// goSymbols lists the declarations in src. A file with syntax errors
+// still yields the declarations the parser could recover; file is nil
+// only when not even the package clause could be read.
+func goSymbols(filename, src string) ([]goSymbol, error) {
+    fset := token.NewFileSet()
+    file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+    if file == nil {
+        return nil, err
+    }
+
+    var symbols []goSymbol
+    add := func(ident *ast.Ident, name, kind string) {
+        if ident == nil || ident.Name == "_" {
+            return
+        }
+        pos := fset.Position(ident.Pos())
+        symbols = append(symbols, goSymbol{name, kind, pos.Line - 1, pos.Column - 1})
+    }
+
+    for _, decl := range file.Decls {
+        switch d := decl.(type) {
+        case *ast.FuncDecl:
+            if d.Recv != nil && len(d.Recv.List) > 0 {
+                add(d.Name, receiverName(d.Recv.List[0].Type)+"."+d.Name.Name, "method")
+            } else {
+                add(d.Name, d.Name.Name, "func")
+            }
+        case *ast.GenDecl:
+            for _, spec := range d.Specs {
+                switch s := spec.(type) {
+                case *ast.TypeSpec:
+                    add(s.Name, s.Name.Name, "type")
+                case *ast.ValueSpec:
+                    kind := "var"
+                    if d.Tok == token.CONST {
+                        kind = "const"
+                    }
+                    for _, name := range s.Names {
+                        add(name, name.Name, kind)
+                    }
+                }
+            }
+        }
+    }
+    return symbols, err
+}
+
+// receiverName returns the type name of a method receiver, without
+// pointer or type parameters.
+func receiverName(expr ast.Expr) string {
+    for {
+        switch t := expr.(type) {
+        case *ast.StarExpr:
+            expr = t.X
+        case *ast.ParenExpr:
+            expr = t.X
+        case *ast.IndexExpr:
+            expr = t.X
+        case *ast.IndexListExpr:
+            expr = t.X
+        case *ast.Ident:
+            return t.Name
+        default:
+            return "?"
+        }
+    }
+}
+
+func (e *Editor) isGoTab(tab *Tab) bool {
+    return tab != nil && e.languageFor(tab).Name == "go"
+}
+
+// symbols returns the declarations of a Go buffer, parsing it again
+// only when its text has changed. When the text cannot be parsed at
+// all the previous symbols are kept.
+func (e *Editor) symbols(tab *Tab) ([]goSymbol, error) {
+    c := &e.outline
+    text := tab.buffer.GetText()
+    if c.buffer == tab.buffer && c.text == text {
+        return c.symbols, c.err
+    }
+
+    symbols, err := goSymbols(tab.buffer.filename, text)
+    if symbols == nil && err != nil && c.buffer == tab.buffer {
+        symbols = c.symbols
+    }
+    *c = outlineCache{buffer: tab.buffer, text: text, symbols: symbols, err: err}
+    return symbols, err
+}
+
+// outlineWidth is the width of the outline panel, or 0 when it is not
+// shown.
+func (e *Editor) outlineWidth() int {
+    if !e.showOutline || e.width < minScreenForOutline || !e.isGoTab(e.tabManager.GetActiveTab()) {
+        return 0
+    }
+    width := e.width / 3
+    if width > maxOutlineWidth {
+        width = maxOutlineWidth
+    }
+    return width
+}
+
+func (e *Editor) toggleOutline(tab *Tab) {
+    if !e.isGoTab(tab) {
+        e.setStatusMsg("The outline is only available for Go files")
+        return
+    }
+    e.showOutline = !e.showOutline
+    if e.showOutline {
+        e.setStatusMsg("Outline shown")
+    } else {
+        e.setStatusMsg("Outline hidden")
+    }
+}
+
+func symbolKindStyle(kind string) (rune, tcell.Style) {
+    switch kind {
+    case "func":
+        return 'f', tcell.StyleDefault.Foreground(tcell.ColorYellow)
+    case "method":
+        return 'm', tcell.StyleDefault.Foreground(tcell.ColorYellow)
+    case "type":
+        return 't', tcell.StyleDefault.Foreground(tcell.ColorGreen)
+    case "const":
+        return 'c', tcell.StyleDefault.Foreground(tcell.ColorTeal)
+    default:
+        return 'v', tcell.StyleDefault.Foreground(tcell.ColorTeal)
+    }
+}
+
+// drawSymbol draws one symbol line starting at x, at most width cells.
+func (e *Editor) drawSymbol(x, y, width int, sym goSymbol, selected bool) {
+    letter, kindStyle := symbolKindStyle(sym.kind)
+    style := tcell.StyleDefault
+    if selected {
+        style = style.Reverse(true)
+        kindStyle = kindStyle.Reverse(true)
+    }
+    end := x + width
+    e.screen.SetContent(x, y, letter, nil, kindStyle)
+    e.screen.SetContent(x+1, y, ' ', nil, style)
+    cx := x + 2
+    for _, r := range sym.name {
+        if cx >= end {
+            break
+        }
+        e.screen.SetContent(cx, y, r, nil, style)
+        cx++
+    }
+    for ; selected && cx < end; cx++ {
+        e.screen.SetContent(cx, y, ' ', nil, style)
+    }
+}
+
+// renderOutline draws the symbols of the active Go buffer in a panel on
+// the right, marking the declaration the cursor is in.
+func (e *Editor) renderOutline(tab *Tab) {
+    width := e.outlineWidth()
+    if width == 0 {
+        return
+    }
+    x := e.width - width
+    sep := tcell.StyleDefault.Foreground(tcell.ColorGray)
+    for y := 1; y <= e.height; y++ {
+        for cx := x; cx < e.width; cx++ {
+            e.screen.SetContent(cx, y, ' ', nil, tcell.StyleDefault)
+        }
+        e.screen.SetContent(x, y, '│', nil, sep)
+    }
+
+    symbols, err := e.symbols(tab)
+    current := -1
+    for i, sym := range symbols {
+        if sym.row <= tab.cursor.Row {
+            current = i
+        }
+    }
+
+    rows := e.height
+    if err != nil {
+        rows--
+        e.drawString(x+1, e.height, truncate("syntax error", width-1), tcell.StyleDefault.Foreground(tcell.ColorRed))
+    }
+    top := 0
+    if current >= rows {
+        top = current - rows/2
+    }
+    for y := 0; y < rows && top+y < len(symbols); y++ {
+        e.drawSymbol(x+1, y+1, width-1, symbols[top+y], top+y == current)
+    }
+}
+
+func truncate(s string, width int) string {
+    if width < 0 {
+        return ""
+    }
+    if len(s) > width {
+        return s[:width]
+    }
+    return s
+}
+
+func (e *Editor) startSymbolPicker(tab *Tab) {
+    if !e.isGoTab(tab) {
+        e.setStatusMsg("Jump to symbol is only available for Go files")
+        return
+    }
+    e.symbolPicker = &symbolPicker{}
+    e.mode = ModeSymbol
+    e.rankSymbols(tab)
+}
+
+// rankSymbols filters the symbols by the fuzzy query. An empty query
+// lists them in source order; an exact name match always comes first.
+func (e *Editor) rankSymbols(tab *Tab) {
+    p := e.symbolPicker
+    symbols, _ := e.symbols(tab)
+
+    type scored struct {
+        sym   goSymbol
+        score int
+    }
+    var ranked []scored
+    for _, sym := range symbols {
+        score, _, ok := fuzzyScore(p.query, sym.name)
+        if !ok {
+            continue
+        }
+        name := sym.name[strings.LastIndexByte(sym.name, '.')+1:]
+        if strings.EqualFold(name, p.query) || strings.EqualFold(sym.name, p.query) {
+            score += 100
+        }
+        ranked = append(ranked, scored{sym, score})
+    }
+    if p.query != "" {
+        sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+    }
+
+    p.matches = p.matches[:0]
+    for _, r := range ranked {
+        p.matches = append(p.matches, r.sym)
+    }
+    p.selected, p.top = 0, 0
+}
+
+// jumpToSymbol moves the cursor to the declaration of the named symbol,
+// preferring an exact match.
+func (e *Editor) jumpToSymbol(tab *Tab, query string) error {
+    if !e.isGoTab(tab) {
+        return fmt.Errorf("not a Go file")
+    }
+    e.symbolPicker = &symbolPicker{query: query}
+    e.rankSymbols(tab)
+    p := e.symbolPicker
+    e.symbolPicker = nil
+    if len(p.matches) == 0 {
+        return fmt.Errorf("no symbol matches '%s'", query)
+    }
+    e.gotoSymbol(tab, p.matches[0])
+    return nil
+}
+
+func (e *Editor) gotoSymbol(tab *Tab, sym goSymbol) {
+    tab.clearSelection()
+    tab.cursor.Row, tab.cursor.Col = sym.row, sym.col
+    e.ensureCursorValid(tab)
+    tab.offsetRow = tab.cursor.Row - e.height/3
+    if tab.offsetRow < 0 {
+        tab.offsetRow = 0
+    }
+    tab.offsetSub = 0
+    e.setStatusMsg(fmt.Sprintf("%s %s (line %d)", sym.kind, sym.name, sym.row+1))
+}
+
+func (e *Editor) handleSymbolMode(ev *tcell.EventKey) bool {
+    tab := e.tabManager.GetActiveTab()
+    p := e.symbolPicker
+    if p == nil || tab == nil || tab.buffer == nil || tab.cursor == nil {
+        e.mode = ModeNormal
+        return true
+    }
+
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.symbolPicker = nil
+        e.mode = ModeNormal
+        e.setStatusMsg("")
+        return true
+    case tcell.KeyEnter:
+        e.symbolPicker = nil
+        e.mode = ModeNormal
+        if p.selected < len(p.matches) {
+            e.gotoSymbol(tab, p.matches[p.selected])
+        }
+        return true
+    case tcell.KeyUp:
+        p.selected--
+    case tcell.KeyDown:
+        p.selected++
+    case tcell.KeyPgUp:
+        p.selected -= e.height
+    case tcell.KeyPgDn:
+        p.selected += e.height
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(p.query) > 0 {
+            _, size := utf8.DecodeLastRuneInString(p.query)
+            p.query = p.query[:len(p.query)-size]
+            e.rankSymbols(tab)
+        }
+    case tcell.KeyRune:
+        p.query += string(ev.Rune())
+        e.rankSymbols(tab)
+    }
+    if p.selected >= len(p.matches) {
+        p.selected = len(p.matches) - 1
+    }
+    if p.selected < 0 {
+        p.selected = 0
+    }
+    return true
+}
+
+// renderSymbolPicker draws the matching symbols over the text area.
+func (e *Editor) renderSymbolPicker() {
+    p := e.symbolPicker
+    if p.selected < p.top {
+        p.top = p.selected
+    }
+    if p.selected >= p.top+e.height {
+        p.top = p.selected - e.height + 1
+    }
+
+    for y := 0; y < e.height && p.top+y < len(p.matches); y++ {
+        sym := p.matches[p.top+y]
+        line := fmt.Sprintf("%6d ", sym.row+1)
+        e.drawString(0, y+1, line, tcell.StyleDefault.Foreground(tcell.ColorGray))
+        e.drawSymbol(len(line), y+1, e.width-len(line), sym, p.top+y == p.selected)
+    }
+
+    e.setStatusMsg(fmt.Sprintf("Go to symbol: %s  (%d matches) | Enter: jump, Esc: cancel", p.query, len(p.matches)))
+}
@@ -0,0 +1,136 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "github.com/gdamore/tcell/v2"
+)
+
+// PopupItem is one entry of a Popup. Insert, when set, is the text a
+// completion puts in instead of Label.
+type PopupItem struct {
+    Label  string
+    Detail string
+    Insert string
+    filter string
+}
+
+// Popup is a scrollable list drawn over the text area, anchored at a
+// screen position. It is used for completion and other pick lists.
+type Popup struct {
+    Items    []PopupItem
+    Selected int
+    MaxRows  int
+    offset   int
+}
+
+func NewPopup(items []PopupItem, maxRows int) *Popup {
+    return &Popup{Items: items, MaxRows: maxRows}
+}
+
+func (p *Popup) SetItems(items []PopupItem) {
+    p.Items = items
+    p.Selected = 0
+    p.offset = 0
+}
+
+func (p *Popup) Move(delta int) {
+    if len(p.Items) == 0 {
+        return
+    }
+    p.Selected = (p.Selected + delta + len(p.Items)) % len(p.Items)
+    if p.Selected < p.offset {
+        p.offset = p.Selected
+    }
+    if p.Selected >= p.offset+p.MaxRows {
+        p.offset = p.Selected - p.MaxRows + 1
+    }
+}
+
+func (p *Popup) Current() (PopupItem, bool) {
+    if p.Selected < 0 || p.Selected >= len(p.Items) {
+        return PopupItem{}, false
+    }
+    return p.Items[p.Selected], true
+}
+
+// Draw renders the popup below (x, y), or above it when there is not
+// enough room, clipped to the text area rows top..bottom.
+func (p *Popup) Draw(screen tcell.Screen, x, y, top, bottom, screenWidth int) {
+    rows := len(p.Items)
+    if rows > p.MaxRows {
+        rows = p.MaxRows
+    }
+    if rows == 0 {
+        return
+    }
+
+    width := 0
+    for _, item := range p.Items {
+        w := len(item.Label)
+        if item.Detail != "" {
+            w += 2 + len(item.Detail)
+        }
+        if w > width {
+            width = w
+        }
+    }
+    width += 2
+    if width > screenWidth {
+        width = screenWidth
+    }
+    if x+width > screenWidth {
+        x = screenWidth - width
+    }
+    if x < 0 {
+        x = 0
+    }
+
+    startY := y + 1
+    if startY+rows-1 > bottom && y-rows >= top {
+        startY = y - rows
+    }
+
+    style := tcell.StyleDefault.Background(tcell.ColorDarkSlateGray).Foreground(tcell.ColorWhite)
+    selStyle := tcell.StyleDefault.Background(tcell.ColorTeal).Foreground(tcell.ColorWhite).Bold(true)
+    detailStyle := style.Foreground(tcell.ColorSilver)
+
+    for i := 0; i < rows; i++ {
+        sy := startY + i
+        if sy < top || sy > bottom {
+            continue
+        }
+        item := p.Items[p.offset+i]
+        lineStyle := style
+        if p.offset+i == p.Selected {
+            lineStyle = selStyle
+        }
+
+        for dx := 0; dx < width; dx++ {
+            screen.SetContent(x+dx, sy, ' ', nil, lineStyle)
+        }
+        dx := 1
+        for _, r := range item.Label {
+            if dx >= width-1 {
+                break
+            }
+            screen.SetContent(x+dx, sy, r, nil, lineStyle)
+            dx++
+        }
+        if item.Detail != "" {
+            dx = width - 1 - len(item.Detail)
+            ds := detailStyle
+            if p.offset+i == p.Selected {
+                ds = lineStyle
+            }
+            for _, r := range item.Detail {
+                if dx >= 1 && dx < width-1 {
+                    screen.SetContent(x+dx, sy, r, nil, ds)
+                }
+                dx++
+            }
+        }
+    }
+}
@@ -0,0 +1,467 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+// replaceHunk is a run of consecutive changed lines that can be
+// included in or left out of a project replace.
+type replaceHunk struct {
+    start   int
+    old     []string
+    new     []string
+    include bool
+}
+
+// fileReplace holds the planned changes for one file. tab is set when
+// the file is open, in which case the change goes through its buffer.
+type fileReplace struct {
+    path    string
+    tab     *Tab
+    charset string
+    lines   []string
+    hunks   []*replaceHunk
+}
+
+type projectReplace struct {
+    query    string
+    template string
+    files    []*fileReplace
+    selected int
+    top      int
+    note     string
+}
+
+// replaceBackup remembers a file written by a project replace, so that
+// it can be undone later.
+type replaceBackup struct {
+    path   string
+    tab    *Tab
+    before []byte
+    after  []byte
+}
+
+// replaceLine returns line with all matches of re replaced.
+func replaceLine(re *regexp.Regexp, template string, regex bool, line string) string {
+    var out strings.Builder
+    last := 0
+    for _, g := range re.FindAllStringSubmatchIndex(line, -1) {
+        if g[1] == g[0] {
+            continue
+        }
+        out.WriteString(line[last:g[0]])
+        if regex {
+            out.WriteString(expandReplacement(re, template, line, g))
+        } else {
+            out.WriteString(template)
+        }
+        last = g[1]
+    }
+    out.WriteString(line[last:])
+    return out.String()
+}
+
+// diffHunks groups the lines changed by the replacement into hunks.
+func diffHunks(lines []string, re *regexp.Regexp, template string, regex bool) []*replaceHunk {
+    var hunks []*replaceHunk
+    var current *replaceHunk
+    for row, line := range lines {
+        replaced := replaceLine(re, template, regex, line)
+        if replaced == line {
+            current = nil
+            continue
+        }
+        if current == nil {
+            current = &replaceHunk{start: row, include: true}
+            hunks = append(hunks, current)
+        }
+        current.old = append(current.old, line)
+        current.new = append(current.new, replaced)
+    }
+    return hunks
+}
+
+// readLines returns the lines of a file on disk, decoded with the
+// charset from its EditorConfig. Line endings are kept, so writing the
+// lines back changes nothing but the replaced text.
+func readLines(path string) ([]string, string, error) {
+    charset := ""
+    if ec := findEditorConfig(path); ec != nil {
+        charset = ec.Charset
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, "", err
+    }
+    if isBinary(data) {
+        return nil, "", fmt.Errorf("binary file")
+    }
+    text := string(data)
+    if charset != "" {
+        if text, err = decodeText(data, charset); err != nil {
+            return nil, "", err
+        }
+    }
+    return strings.Split(text, "\n"), charset, nil
+}
+
+func encodeLines(lines []string, charset string) ([]byte, error) {
+    text := strings.Join(lines, "\n")
+    if charset == "" {
+        return []byte(text), nil
+    }
+    return encodeText(text, charset)
+}
+
+// startProjectReplace asks for the replacement of the current grep
+// query in all files that had results.
+func (e *Editor) startProjectReplace() {
+    g := e.grep
+    results, _, done := g.snapshot()
+    if g.re == nil {
+        g.note = "use rename (F2) to change references"
+        return
+    }
+    if !done {
+        g.note = "wait for the search to finish before replacing"
+        return
+    }
+    if len(results) == 0 {
+        g.note = "nothing to replace"
+        return
+    }
+    e.mode = ModeProjectReplaceWith
+    e.inputBuffer = ""
+    e.setStatusMsg(fmt.Sprintf("Replace '%s' in all files with: ", g.query))
+}
+
+func (e *Editor) handleProjectReplaceWithMode(ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeGrepResults
+    case tcell.KeyEnter:
+        e.previewProjectReplace(e.inputBuffer)
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg(fmt.Sprintf("Replace '%s' in all files with: %s", e.grep.query, e.inputBuffer))
+    case tcell.KeyRune:
+        e.inputBuffer += string(ev.Rune())
+        e.setStatusMsg(fmt.Sprintf("Replace '%s' in all files with: %s", e.grep.query, e.inputBuffer))
+    }
+    return true
+}
+
+// previewProjectReplace computes the changes for every file with grep
+// results, reading open files from their buffers, and shows them as a
+// diff.
+func (e *Editor) previewProjectReplace(template string) {
+    g := e.grep
+    results, _, _ := g.snapshot()
+    p := &projectReplace{query: g.query, template: template}
+
+    var failed []string
+    for i, r := range results {
+        if i > 0 && r.path == results[i-1].path {
+            continue
+        }
+        f := &fileReplace{path: r.path}
+        if index := e.tabManager.FindFile(r.path); index >= 0 {
+            f.tab = e.tabManager.tabs[index]
+            f.lines = f.tab.buffer.GetLines(0, f.tab.buffer.LineCount())
+        } else {
+            lines, charset, err := readLines(r.path)
+            if err != nil {
+                failed = append(failed, r.path)
+                continue
+            }
+            f.lines, f.charset = lines, charset
+        }
+        f.hunks = diffHunks(f.lines, g.re, template, g.regex)
+        if len(f.hunks) > 0 {
+            p.files = append(p.files, f)
+        }
+    }
+
+    if len(p.files) == 0 {
+        e.mode = ModeGrepResults
+        g.note = "no changes to make"
+        return
+    }
+    if len(failed) > 0 {
+        p.note = "could not read " + strings.Join(failed, ", ")
+    }
+    e.projectReplace = p
+    e.mode = ModeProjectReplace
+}
+
+// hunkAt returns the file and hunk with the given index across all
+// files of the preview.
+func (p *projectReplace) hunkAt(index int) (*fileReplace, *replaceHunk) {
+    for _, f := range p.files {
+        if index < len(f.hunks) {
+            return f, f.hunks[index]
+        }
+        index -= len(f.hunks)
+    }
+    return nil, nil
+}
+
+func (p *projectReplace) hunkCount() int {
+    n := 0
+    for _, f := range p.files {
+        n += len(f.hunks)
+    }
+    return n
+}
+
+func (e *Editor) handleProjectReplaceMode(ev *tcell.EventKey) bool {
+    p := e.projectReplace
+    if p == nil {
+        e.mode = ModeNormal
+        return true
+    }
+
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.projectReplace = nil
+        e.mode = ModeGrepResults
+        return true
+    case tcell.KeyUp:
+        p.selected--
+    case tcell.KeyDown:
+        p.selected++
+    case tcell.KeyPgUp:
+        p.selected -= e.height / 4
+    case tcell.KeyPgDn:
+        p.selected += e.height / 4
+    case tcell.KeyHome:
+        p.selected = 0
+    case tcell.KeyEnd:
+        p.selected = p.hunkCount() - 1
+    case tcell.KeyEnter:
+        e.applyProjectReplace()
+        return true
+    case tcell.KeyRune:
+        f, h := p.hunkAt(p.selected)
+        switch ev.Rune() {
+        case ' ':
+            h.include = !h.include
+            p.selected++
+        case 'f':
+            include := !h.include
+            for _, fh := range f.hunks {
+                fh.include = include
+            }
+        case 'a', 'n':
+            for _, f := range p.files {
+                for _, fh := range f.hunks {
+                    fh.include = ev.Rune() == 'a'
+                }
+            }
+        }
+    }
+    if p.selected >= p.hunkCount() {
+        p.selected = p.hunkCount() - 1
+    }
+    if p.selected < 0 {
+        p.selected = 0
+    }
+    return true
+}
+
+// applyProjectReplace writes the included hunks. Open files are changed
+// in their buffers as one undo step each; other files are written to
+// disk and remembered for undo-replace.
+func (e *Editor) applyProjectReplace() {
+    p := e.projectReplace
+    e.projectReplace = nil
+    e.mode = ModeNormal
+
+    files, lines := 0, 0
+    var failed []string
+    for _, f := range p.files {
+        updated := make([]string, len(f.lines))
+        copy(updated, f.lines)
+        changed := 0
+        for _, h := range f.hunks {
+            if h.include {
+                copy(updated[h.start:], h.new)
+                changed += len(h.new)
+            }
+        }
+        if changed == 0 {
+            continue
+        }
+
+        if err := e.writeReplacement(f, updated); err != nil {
+            failed = append(failed, fmt.Sprintf("%s: %v", f.path, err))
+            continue
+        }
+        files++
+        lines += changed
+    }
+
+    if len(failed) > 0 {
+        e.setStatusMsg("Replace failed for " + strings.Join(failed, "; "))
+        return
+    }
+    e.setStatusMsg(fmt.Sprintf("Changed %d line(s) in %d file(s). undo-replace reverts one file at a time", lines, files))
+}
+
+func (e *Editor) writeReplacement(f *fileReplace, updated []string) error {
+    backup := replaceBackup{path: f.path, tab: f.tab}
+    if f.tab != nil {
+        b := f.tab.buffer
+        backup.before = []byte(b.GetText())
+        b.ReplaceLines(0, b.LineCount(), updated)
+        e.ensureCursorValid(f.tab)
+        b.SaveState(f.tab.cursor.Row, f.tab.cursor.Col)
+        backup.after = []byte(b.GetText())
+        e.replaceBackups = append(e.replaceBackups, backup)
+        return nil
+    }
+
+    info, err := os.Stat(f.path)
+    if err != nil {
+        return err
+    }
+    if backup.before, err = encodeLines(f.lines, f.charset); err != nil {
+        return err
+    }
+    if backup.after, err = encodeLines(updated, f.charset); err != nil {
+        return err
+    }
+    if current, err := os.ReadFile(f.path); err != nil || string(current) != string(backup.before) {
+        return fmt.Errorf("file changed on disk")
+    }
+    if err := os.WriteFile(f.path, backup.after, info.Mode().Perm()); err != nil {
+        return err
+    }
+    e.replaceBackups = append(e.replaceBackups, backup)
+    return nil
+}
+
+// undoReplace reverts the most recent file changed by a project
+// replace, as long as it has not been edited since.
+func (e *Editor) undoReplace() error {
+    if len(e.replaceBackups) == 0 {
+        return fmt.Errorf("no project replace to undo")
+    }
+    backup := e.replaceBackups[len(e.replaceBackups)-1]
+
+    if backup.tab != nil {
+        b := backup.tab.buffer
+        if b.GetText() != string(backup.after) {
+            return fmt.Errorf("%s has changed since the replace", backup.path)
+        }
+        b.ReplaceLines(0, b.LineCount(), strings.Split(string(backup.before), "\n"))
+        e.ensureCursorValid(backup.tab)
+        b.SaveState(backup.tab.cursor.Row, backup.tab.cursor.Col)
+    } else {
+        current, err := os.ReadFile(backup.path)
+        if err != nil {
+            return err
+        }
+        if string(current) != string(backup.after) {
+            return fmt.Errorf("%s has changed since the replace", backup.path)
+        }
+        info, err := os.Stat(backup.path)
+        if err != nil {
+            return err
+        }
+        if err := os.WriteFile(backup.path, backup.before, info.Mode().Perm()); err != nil {
+            return err
+        }
+        if index := e.tabManager.FindFile(backup.path); index >= 0 {
+            if tab := e.tabManager.tabs[index]; !tab.buffer.modified {
+                tab.buffer.Load()
+                e.ensureCursorValid(tab)
+            }
+        }
+    }
+
+    e.replaceBackups = e.replaceBackups[:len(e.replaceBackups)-1]
+    e.setStatusMsg(fmt.Sprintf("Reverted %s (%d more to undo)", backup.path, len(e.replaceBackups)))
+    return nil
+}
+
+// renderProjectReplace draws the diff preview: a header per file and,
+// for every hunk, its old and new lines with a check box.
+func (e *Editor) renderProjectReplace() {
+    p := e.projectReplace
+
+    type previewRow struct {
+        text  string
+        style tcell.Style
+    }
+    var rows []previewRow
+    selRow := 0
+    headerStyle := tcell.StyleDefault.Foreground(tcell.ColorBlue).Bold(true)
+    included, total := 0, 0
+    for _, f := range p.files {
+        count := 0
+        for _, h := range f.hunks {
+            if h.include {
+                count++
+            }
+        }
+        where := "disk"
+        if f.tab != nil {
+            where = "open buffer"
+        }
+        rows = append(rows, previewRow{fmt.Sprintf("%s (%d of %d hunks, %s)", f.path, count, len(f.hunks), where), headerStyle})
+
+        for _, h := range f.hunks {
+            mark := "[ ]"
+            if h.include {
+                mark = "[x]"
+                included++
+            }
+            style := tcell.StyleDefault.Foreground(tcell.ColorGray)
+            if total == p.selected {
+                selRow = len(rows)
+                style = style.Reverse(true)
+            }
+            total++
+            rows = append(rows, previewRow{fmt.Sprintf("%s @@ line %d", mark, h.start+1), style})
+            for _, line := range h.old {
+                rows = append(rows, previewRow{"    -" + line, tcell.StyleDefault.Foreground(tcell.ColorRed)})
+            }
+            for _, line := range h.new {
+                rows = append(rows, previewRow{"    +" + line, tcell.StyleDefault.Foreground(tcell.ColorGreen)})
+            }
+        }
+    }
+
+    if selRow < p.top+1 {
+        p.top = selRow - 1
+    }
+    if selRow >= p.top+e.height {
+        p.top = selRow - e.height + 1
+    }
+    if p.top < 0 {
+        p.top = 0
+    }
+
+    for y := 0; y < e.height && p.top+y < len(rows); y++ {
+        row := rows[p.top+y]
+        e.drawString(0, y+1, strings.ReplaceAll(row.text, "\t", "    "), row.style)
+    }
+
+    status := fmt.Sprintf("Replace '%s' with '%s': %d of %d hunks", p.query, p.template, included, total)
+    if p.note != "" {
+        status += " - " + p.note
+    }
+    e.setStatusMsg(status + " | Space: toggle, f: file, a/n: all/none, Enter: apply, Esc: back")
+}
@@ -0,0 +1,241 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "strings"
+    "unicode/utf8"
+)
+
+const defaultWrapWidth = 80
+
+// linePrefix is the part of a line that reflow keeps in front of the
+// text: indentation, a comment or quote marker, and a list bullet.
+type linePrefix struct {
+    indent string
+    marker string
+    gap    string
+    bullet string
+    rest   string
+}
+
+func (p linePrefix) first() string {
+    return p.indent + p.marker + p.gap + p.bullet
+}
+
+// continuation is the prefix of the following lines of the paragraph,
+// aligned with the text after the bullet.
+func (p linePrefix) continuation() string {
+    return p.indent + p.marker + p.gap + strings.Repeat(" ", utf8.RuneCountInString(p.bullet))
+}
+
+// reflowMarkers returns the line prefixes kept by reflow: the language's
+// line comment, or the > of quoted mail and Markdown in prose.
+func (l *Language) reflowMarkers() []string {
+    if l.LineComment != "" {
+        return []string{l.LineComment}
+    }
+    if l.Name == "text" || l.Name == "markdown" {
+        return []string{">"}
+    }
+    return nil
+}
+
+func parsePrefix(line string, markers []string) linePrefix {
+    var p linePrefix
+    p.indent = leadingWhitespace(line)
+    rest := line[len(p.indent):]
+
+    for _, m := range markers {
+        if strings.HasPrefix(rest, m) {
+            p.marker = m
+            rest = rest[len(m):]
+            break
+        }
+    }
+    if p.marker != "" {
+        p.gap = leadingWhitespace(rest)
+        rest = rest[len(p.gap):]
+    }
+
+    p.bullet = listBullet(rest)
+    p.rest = rest[len(p.bullet):]
+    return p
+}
+
+// listBullet returns a leading "- ", "* ", "+ ", "1. " or "1) " item
+// marker including the spaces after it.
+func listBullet(text string) string {
+    i := 0
+    if len(text) > 0 && strings.IndexByte("-*+", text[0]) >= 0 {
+        i = 1
+    } else {
+        for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+            i++
+        }
+        if i == 0 || i > 9 || i >= len(text) || (text[i] != '.' && text[i] != ')') {
+            return ""
+        }
+        i++
+    }
+    if i >= len(text) || text[i] != ' ' {
+        return ""
+    }
+    for i < len(text) && text[i] == ' ' {
+        i++
+    }
+    return text[:i]
+}
+
+// reflowLines rewraps every paragraph in lines to width. Lines without
+// text separate paragraphs and are kept as they are.
+func reflowLines(lines []string, width int, markers []string) []string {
+    var result []string
+    var words []string
+    var head, cont string
+
+    flush := func() {
+        if len(words) == 0 {
+            return
+        }
+        result = append(result, fillWords(words, head, cont, width)...)
+        words = nil
+    }
+
+    for _, line := range lines {
+        p := parsePrefix(line, markers)
+        if strings.TrimSpace(p.rest) == "" {
+            flush()
+            result = append(result, line)
+            continue
+        }
+
+        if len(words) == 0 || p.bullet != "" || p.first() != cont {
+            flush()
+            head, cont = p.first(), p.continuation()
+        }
+        words = append(words, strings.Fields(p.rest)...)
+    }
+    flush()
+    return result
+}
+
+func fillWords(words []string, head, cont string, width int) []string {
+    var result []string
+    line := head
+    lineWords := 0
+    for _, w := range words {
+        if lineWords > 0 && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(w) > width {
+            result = append(result, line)
+            line = cont
+            lineWords = 0
+        }
+        if lineWords > 0 {
+            line += " "
+        }
+        line += w
+        lineWords++
+    }
+    return append(result, line)
+}
+
+// wrapWidth is the EditorConfig max_line_length of the buffer, or the
+// configured wrap width.
+func (e *Editor) wrapWidth(tab *Tab) int {
+    if ec := tab.buffer.editorConfig; ec != nil && ec.MaxLineLength > 0 {
+        return ec.MaxLineLength
+    }
+    if e.config.WrapWidth > 0 {
+        return e.config.WrapWidth
+    }
+    return defaultWrapWidth
+}
+
+// paragraphRange finds the lines around the cursor that share its
+// comment marker and contain text. A list item ends the range.
+func (e *Editor) paragraphRange(tab *Tab, markers []string) (int, int) {
+    row := tab.cursor.Row
+    marker := parsePrefix(tab.buffer.GetLine(row), markers).marker
+    same := func(r int) bool {
+        q := parsePrefix(tab.buffer.GetLine(r), markers)
+        return strings.TrimSpace(q.rest) != "" && q.marker == marker
+    }
+    if !same(row) {
+        return row, row
+    }
+
+    start, end := row, row+1
+    for start > 0 && same(start-1) && parsePrefix(tab.buffer.GetLine(start), markers).bullet == "" {
+        start--
+    }
+    for end < tab.buffer.LineCount() && same(end) && parsePrefix(tab.buffer.GetLine(end), markers).bullet == "" {
+        end++
+    }
+    return start, end
+}
+
+// reflow rewraps the selected lines, or the paragraph at the cursor, as
+// a single undo step.
+func (e *Editor) reflow(tab *Tab, width int) {
+    if width <= 0 {
+        width = e.wrapWidth(tab)
+    }
+    markers := e.languageFor(tab).reflowMarkers()
+
+    start, end := tab.selectedLines()
+    if !tab.selActive {
+        start, end = e.paragraphRange(tab, markers)
+    }
+    if start >= end {
+        e.setStatusMsg("No paragraph at cursor")
+        return
+    }
+
+    lines := reflowLines(tab.buffer.GetLines(start, end), width, markers)
+    tab.buffer.ReplaceLines(start, end, lines)
+
+    tab.clearSelection()
+    tab.cursor.Row = start + len(lines) - 1
+    tab.cursor.Col = len(lines[len(lines)-1])
+    e.commitLines(tab, fmt.Sprintf("Reflowed %d line(s) to width %d", end-start, width))
+}
+
+// wrapWhileTyping breaks the current line at the last space before the
+// wrap width once typing has pushed it past the limit.
+func (e *Editor) wrapWhileTyping(tab *Tab) {
+    width := e.wrapWidth(tab)
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    if utf8.RuneCountInString(line) <= width {
+        return
+    }
+
+    p := parsePrefix(line, e.languageFor(tab).reflowMarkers())
+    textStart := len(p.first())
+
+    limit := 0
+    for i := range line {
+        if utf8.RuneCountInString(line[:i]) > width {
+            break
+        }
+        limit = i
+    }
+
+    split := strings.LastIndexByte(line[:limit+1], ' ')
+    if split <= textStart {
+        return
+    }
+
+    head := strings.TrimRight(line[:split], " ")
+    tail := strings.TrimLeft(line[split:], " ")
+    cont := p.continuation()
+    tab.buffer.SetLine(tab.cursor.Row, head)
+    tab.buffer.InsertText(tab.cursor.Row, len(head), "\n"+cont+tail)
+
+    if tab.cursor.Col > split {
+        tab.cursor.Col = len(cont) + tab.cursor.Col - (len(line) - len(tail))
+        tab.cursor.Row++
+    }
+}
@@ -0,0 +1,394 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+// replaceSession is the state of a running find and replace. scope is
+// nil when the whole buffer is searched, otherwise it holds the start
+// and end of the selection the replace started from. A whole-buffer
+// replace starts at origin, the cursor, and wraps around to the start
+// of the buffer to stop there again.
+type replaceSession struct {
+    tab      *Tab
+    re       *regexp.Regexp
+    template string
+    regex    bool
+    scope    *[2]Cursor
+    origin   Cursor
+    wrapped  bool
+    from     Cursor
+    current  searchMatch
+    count    int
+}
+
+func (e *Editor) replacePrompt() string {
+    return "Replace" + e.searchOpts.String() + ": " + e.inputBuffer
+}
+
+func (e *Editor) startReplace() {
+    e.mode = ModeReplace
+    e.inputBuffer = ""
+    e.historyIndex = len(e.searchHistory)
+    e.setStatusMsg(e.replacePrompt())
+}
+
+func (e *Editor) handleReplaceMode(ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.setStatusMsg("Replace cancelled")
+    case tcell.KeyEnter:
+        if e.inputBuffer == "" {
+            e.mode = ModeNormal
+            e.setStatusMsg("No search query entered")
+            return true
+        }
+        e.findQuery = e.inputBuffer
+        e.addSearchHistory(e.findQuery)
+        e.inputBuffer = ""
+        e.mode = ModeReplaceWith
+        e.setStatusMsg(fmt.Sprintf("Replace '%s' with: ", e.findQuery))
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg(e.replacePrompt())
+    case tcell.KeyUp:
+        e.browseHistory(-1)
+        e.setStatusMsg(e.replacePrompt())
+    case tcell.KeyDown:
+        e.browseHistory(1)
+        e.setStatusMsg(e.replacePrompt())
+    case tcell.KeyRune:
+        if ev.Modifiers()&tcell.ModAlt != 0 {
+            e.searchOpts.toggle(ev.Rune())
+        } else {
+            e.inputBuffer += string(ev.Rune())
+        }
+        e.setStatusMsg(e.replacePrompt())
+    }
+    return true
+}
+
+func (e *Editor) handleReplaceWithMode(ev *tcell.EventKey) bool {
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.setStatusMsg("Replace cancelled")
+    case tcell.KeyEnter:
+        e.mode = ModeNormal
+        e.beginReplace(e.inputBuffer)
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.setStatusMsg(fmt.Sprintf("Replace '%s' with: %s", e.findQuery, e.inputBuffer))
+    case tcell.KeyRune:
+        e.inputBuffer += string(ev.Rune())
+        e.setStatusMsg(fmt.Sprintf("Replace '%s' with: %s", e.findQuery, e.inputBuffer))
+    }
+    return true
+}
+
+// beginReplace starts stepping through the matches of e.findQuery in
+// the selection, or in the whole buffer starting at the cursor. All
+// replacements of a session form one undo step.
+func (e *Editor) beginReplace(template string) {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil {
+        return
+    }
+
+    re, err := compileSearch(e.findQuery, e.searchOpts)
+    if err != nil {
+        e.actionFailed = true
+        e.setStatusMsg(err.Error())
+        return
+    }
+
+    s := &replaceSession{tab: tab, re: re, template: template, regex: e.searchOpts.Regex, origin: *tab.cursor, from: *tab.cursor}
+    if startRow, startCol, endRow, endCol, ok := tab.selectionRange(); ok {
+        s.scope = &[2]Cursor{{Row: startRow, Col: startCol}, {Row: endRow, Col: endCol}}
+        s.from = s.scope[0]
+    }
+    tab.clearSelection()
+
+    e.replace = s
+    tab.buffer.BeginBatch()
+    e.nextReplaceMatch(tab)
+}
+
+// replaceMatches returns the matches inside the session's scope that
+// start at or after from. After wrapping around, the scope ends at the
+// origin.
+func (e *Editor) replaceMatches(tab *Tab, from Cursor) []searchMatch {
+    s := e.replace
+    var result []searchMatch
+    for _, m := range findMatches(tab.buffer, s.re, e.searchOpts.Multiline) {
+        if m.row < from.Row || m.row == from.Row && m.col < from.Col {
+            continue
+        }
+        end := s.origin
+        if s.scope != nil {
+            end = s.scope[1]
+        }
+        if (s.scope != nil || s.wrapped) && (m.endRow > end.Row || m.endRow == end.Row && m.endCol > end.Col) {
+            break
+        }
+        result = append(result, m)
+    }
+    return result
+}
+
+// wrapReplace continues a whole-buffer replace at the start of the
+// buffer. It reports false when the session has already wrapped.
+func (e *Editor) wrapReplace() bool {
+    s := e.replace
+    if s.scope != nil || s.wrapped {
+        return false
+    }
+    s.wrapped = true
+    s.from = Cursor{}
+    return true
+}
+
+// nextReplaceMatch selects the next match and asks for confirmation, or
+// finishes the session when there are no more.
+func (e *Editor) nextReplaceMatch(tab *Tab) {
+    matches := e.replaceMatches(tab, e.replace.from)
+    if len(matches) == 0 && e.wrapReplace() {
+        matches = e.replaceMatches(tab, e.replace.from)
+    }
+    if len(matches) == 0 {
+        e.finishReplace(tab)
+        return
+    }
+
+    e.replace.current = matches[0]
+    selectMatch(tab, matches[0])
+    e.mode = ModeReplaceConfirm
+    e.setStatusMsg(fmt.Sprintf("Replace this match? (y)es (n)o (a)ll (q)uit  [%d replaced]", e.replace.count))
+}
+
+// abortReplace ends a replace session that is left without answering
+// the confirmation, e.g. when macro playback stops, so its undo batch
+// is closed.
+func (e *Editor) abortReplace() {
+    if e.replace != nil {
+        e.finishReplace(e.replace.tab)
+    }
+}
+
+func (e *Editor) finishReplace(tab *Tab) {
+    s := e.replace
+    e.replace = nil
+    e.mode = ModeNormal
+    tab.clearSelection()
+    e.ensureCursorValid(tab)
+    tab.buffer.EndBatch(tab.cursor.Row, tab.cursor.Col)
+    if s.count == 0 {
+        e.actionFailed = true
+        e.setStatusMsg(fmt.Sprintf("'%s' not found", e.findQuery))
+        return
+    }
+    e.setStatusMsg(fmt.Sprintf("Replaced %d occurrence(s)", s.count))
+}
+
+func (e *Editor) handleReplaceConfirmMode(ev *tcell.EventKey) bool {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil || e.replace == nil {
+        e.mode = ModeNormal
+        return true
+    }
+
+    s := e.replace
+    key := ev.Rune()
+    if ev.Key() == tcell.KeyEscape {
+        key = 'q'
+    } else if ev.Key() != tcell.KeyRune {
+        return true
+    }
+
+    switch key {
+    case 'y':
+        end := e.replaceOne(tab, s.current)
+        s.from = end
+        e.nextReplaceMatch(tab)
+    case 'n':
+        s.from = Cursor{Row: s.current.endRow, Col: s.current.endCol}
+        e.nextReplaceMatch(tab)
+    case 'a':
+        matches := e.replaceMatches(tab, Cursor{Row: s.current.row, Col: s.current.col})
+        if e.wrapReplace() {
+            matches = append(e.replaceMatches(tab, s.from), matches...)
+        }
+        texts := e.replacementsFor(tab, matches)
+        for i := len(matches) - 1; i >= 0; i-- {
+            e.applyReplacement(tab, matches[i], texts[i])
+        }
+        if len(matches) > 0 {
+            tab.cursor.Row, tab.cursor.Col = matches[0].row, matches[0].col
+        }
+        e.finishReplace(tab)
+    case 'q':
+        e.finishReplace(tab)
+    }
+    return true
+}
+
+// replaceOne replaces a single match and returns the position after the
+// inserted text.
+func (e *Editor) replaceOne(tab *Tab, m searchMatch) Cursor {
+    end := e.applyReplacement(tab, m, e.replacementsFor(tab, []searchMatch{m})[0])
+    tab.cursor.Row, tab.cursor.Col = m.row, m.col
+    return end
+}
+
+func (e *Editor) applyReplacement(tab *Tab, m searchMatch, text string) Cursor {
+    tab.buffer.DeleteRange(m.row, m.col, m.endRow, m.endCol)
+    tab.buffer.InsertText(m.row, m.col, text)
+    tab.buffer.SaveState(m.row, m.col)
+    e.replace.count++
+
+    lines := strings.Split(text, "\n")
+    end := Cursor{Row: m.row + len(lines) - 1, Col: len(lines[len(lines)-1])}
+    if len(lines) == 1 {
+        end.Col += m.col
+    }
+
+    // Keep the end of the selection scope, and the origin once it lies
+    // after the replaced text, on the same text.
+    shift := func(c *Cursor) {
+        if c.Row == m.endRow {
+            c.Col += end.Col - m.endCol
+        }
+        c.Row += end.Row - m.endRow
+    }
+    if scope := e.replace.scope; scope != nil {
+        shift(&scope[1])
+    } else if e.replace.wrapped {
+        shift(&e.replace.origin)
+    }
+    return end
+}
+
+// replacementsFor expands the template for each match, running the
+// regex once per line, or once over the buffer in multiline mode. In
+// regex mode $1 and ${name} insert groups, \U, \L and \E change the case
+// of what follows, \u and \l the case of the next character, and \n and
+// \t insert a newline or tab.
+func (e *Editor) replacementsFor(tab *Tab, matches []searchMatch) []string {
+    s := e.replace
+    texts := make([]string, len(matches))
+    if !s.regex {
+        for i := range texts {
+            texts[i] = s.template
+        }
+        return texts
+    }
+
+    var src string
+    var starts []int
+    groups := make(map[int][]int)
+    index := func(text string) {
+        src = text
+        groups = make(map[int][]int)
+        for _, g := range s.re.FindAllStringSubmatchIndex(src, -1) {
+            groups[g[0]] = g
+        }
+    }
+    if e.searchOpts.Multiline {
+        index(tab.buffer.GetText())
+        offset := 0
+        for row := 0; row < tab.buffer.LineCount(); row++ {
+            starts = append(starts, offset)
+            offset += len(tab.buffer.GetLine(row)) + 1
+        }
+    }
+
+    row := -1
+    for i, m := range matches {
+        offset := m.col
+        if starts != nil {
+            offset += starts[m.row]
+        } else if m.row != row {
+            row = m.row
+            index(tab.buffer.GetLine(row))
+        }
+        texts[i] = s.template
+        if g, ok := groups[offset]; ok {
+            texts[i] = expandReplacement(s.re, s.template, src, g)
+        }
+    }
+    return texts
+}
+
+func expandReplacement(re *regexp.Regexp, template, src string, groups []int) string {
+    var out strings.Builder
+    caseMode, nextCase := byte(0), byte(0)
+
+    write := func(piece string) {
+        if piece == "" {
+            return
+        }
+        if nextCase != 0 {
+            r, size := utf8.DecodeRuneInString(piece)
+            if nextCase == 'u' {
+                out.WriteRune(unicode.ToUpper(r))
+            } else {
+                out.WriteRune(unicode.ToLower(r))
+            }
+            piece = piece[size:]
+            nextCase = 0
+        }
+        switch caseMode {
+        case 'U':
+            piece = strings.ToUpper(piece)
+        case 'L':
+            piece = strings.ToLower(piece)
+        }
+        out.WriteString(piece)
+    }
+
+    for len(template) > 0 {
+        i := strings.IndexByte(template, '\\')
+        if i < 0 {
+            i = len(template)
+        }
+        write(string(re.ExpandString(nil, template[:i], src, groups)))
+        if i >= len(template)-1 {
+            if i == len(template)-1 {
+                write("\\")
+            }
+            break
+        }
+
+        switch c := template[i+1]; c {
+        case 'U', 'L':
+            caseMode = c
+        case 'E':
+            caseMode = 0
+        case 'u', 'l':
+            nextCase = c
+        case 'n':
+            write("\n")
+        case 't':
+            write("\t")
+        default:
+            write(string(c))
+        }
+        template = template[i+2:]
+    }
+    return out.String()
+}
@@ -0,0 +1,409 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const maxSearchHistory = 100
+
+// SearchOptions are the toggles of the find prompt.
+type SearchOptions struct {
+    Regex         bool
+    CaseSensitive bool
+    WholeWord     bool
+    Multiline     bool
+}
+
+// searchMatch is a match from (row, col) up to, not including, (endRow,
+// endCol).
+type searchMatch struct {
+    row    int
+    col    int
+    endRow int
+    endCol int
+}
+
+func (o SearchOptions) String() string {
+    var flags []string
+    if o.Regex {
+        flags = append(flags, "regex")
+    }
+    if o.CaseSensitive {
+        flags = append(flags, "case")
+    }
+    if o.WholeWord {
+        flags = append(flags, "word")
+    }
+    if o.Multiline {
+        flags = append(flags, "multiline")
+    }
+    if len(flags) == 0 {
+        return ""
+    }
+    return " [" + strings.Join(flags, " ") + "]"
+}
+
+// toggle flips the option bound to Alt+r with the find prompt open:
+// r regex, c case, w whole word, m multiline.
+func (o *SearchOptions) toggle(r rune) bool {
+    switch r {
+    case 'r':
+        o.Regex = !o.Regex
+    case 'c':
+        o.CaseSensitive = !o.CaseSensitive
+    case 'w':
+        o.WholeWord = !o.WholeWord
+    case 'm':
+        o.Multiline = !o.Multiline
+    default:
+        return false
+    }
+    return true
+}
+
+// compileSearch builds the regular expression for a query. Plain
+// queries are matched literally.
+func compileSearch(query string, opts SearchOptions) (*regexp.Regexp, error) {
+    pattern := query
+    if opts.Regex {
+        // Check the query alone so errors quote what the user typed.
+        if _, err := regexp.Compile(query); err != nil {
+            return nil, fmt.Errorf("Invalid pattern: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+        }
+    } else {
+        pattern = regexp.QuoteMeta(query)
+    }
+    if opts.WholeWord {
+        pattern = `\b(?:` + pattern + `)\b`
+    }
+    if opts.Multiline {
+        pattern = `(?m)` + pattern
+    }
+    if !opts.CaseSensitive {
+        pattern = `(?i)` + pattern
+    }
+    return regexp.Compile(pattern)
+}
+
+// findMatches returns every non-empty match in the buffer in order. In
+// multiline mode the pattern runs over the whole text, so it can match
+// line breaks.
+func findMatches(b *Buffer, re *regexp.Regexp, multiline bool) []searchMatch {
+    var matches []searchMatch
+    if !multiline {
+        for row := 0; row < b.LineCount(); row++ {
+            for _, m := range re.FindAllStringIndex(b.GetLine(row), -1) {
+                if m[1] > m[0] {
+                    matches = append(matches, searchMatch{row, m[0], row, m[1]})
+                }
+            }
+        }
+        return matches
+    }
+
+    starts := make([]int, b.LineCount())
+    offset := 0
+    for row := range starts {
+        starts[row] = offset
+        offset += len(b.GetLine(row)) + 1
+    }
+    position := func(off int) (int, int) {
+        row := sort.Search(len(starts), func(i int) bool { return starts[i] > off }) - 1
+        return row, off - starts[row]
+    }
+
+    for _, m := range re.FindAllStringIndex(b.GetText(), -1) {
+        if m[1] > m[0] {
+            row, col := position(m[0])
+            endRow, endCol := position(m[1])
+            matches = append(matches, searchMatch{row, col, endRow, endCol})
+        }
+    }
+    return matches
+}
+
+func (m searchMatch) after(row, col int) bool {
+    return m.row > row || m.row == row && m.col > col
+}
+
+// selectMatch moves the cursor to the start of a match and selects it.
+func selectMatch(tab *Tab, m searchMatch) {
+    tab.selAnchor = Cursor{Row: m.endRow, Col: m.endCol}
+    tab.selActive = true
+    tab.cursor.Row = m.row
+    tab.cursor.Col = m.col
+}
+
+func (e *Editor) searchPrompt() string {
+    return "Find" + e.searchOpts.String() + ": " + e.inputBuffer
+}
+
+// startFind opens the find prompt, remembering the cursor so Escape can
+// return to it.
+func (e *Editor) startFind(tab *Tab) {
+    e.mode = ModeFind
+    e.inputBuffer = ""
+    e.searchOrigin = *tab.cursor
+    e.searchMatches = nil
+    e.historyIndex = len(e.searchHistory)
+    e.setStatusMsg(e.searchPrompt())
+}
+
+// updateIncrementalSearch moves to the first match at or after the
+// position the search started from as the query is typed.
+func (e *Editor) updateIncrementalSearch() {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil {
+        return
+    }
+
+    e.searchMatches = nil
+    e.searchCurrent = -1
+    tab.clearSelection()
+    *tab.cursor = e.searchOrigin
+    if e.inputBuffer == "" {
+        e.setStatusMsg(e.searchPrompt())
+        return
+    }
+
+    re, err := compileSearch(e.inputBuffer, e.searchOpts)
+    if err != nil {
+        e.setStatusMsg(e.searchPrompt() + "  (" + err.Error() + ")")
+        return
+    }
+
+    e.searchMatches = findMatches(tab.buffer, re, e.searchOpts.Multiline)
+    if len(e.searchMatches) == 0 {
+        e.setStatusMsg(e.searchPrompt() + "  (no matches)")
+        return
+    }
+
+    origin := e.searchOrigin
+    e.searchCurrent = sort.Search(len(e.searchMatches), func(i int) bool {
+        return !e.searchMatches[i].before(origin.Row, origin.Col)
+    })
+    wrapped := ""
+    if e.searchCurrent == len(e.searchMatches) {
+        e.searchCurrent = 0
+        wrapped = ", wrapped"
+    }
+    selectMatch(tab, e.searchMatches[e.searchCurrent])
+    e.setStatusMsg(fmt.Sprintf("%s  (match %d of %d%s)", e.searchPrompt(), e.searchCurrent+1, len(e.searchMatches), wrapped))
+}
+
+func (m searchMatch) before(row, col int) bool {
+    return m.row < row || m.row == row && m.col < col
+}
+
+// isSearchHit reports whether (row, col) lies inside one of the matches
+// highlighted during incremental search.
+func (e *Editor) isSearchHit(row, col int) bool {
+    i := sort.Search(len(e.searchMatches), func(i int) bool {
+        return e.searchMatches[i].endRow >= row
+    })
+    for ; i < len(e.searchMatches) && e.searchMatches[i].row <= row; i++ {
+        m := e.searchMatches[i]
+        if (row > m.row || col >= m.col) && (row < m.endRow || col < m.endCol) {
+            return true
+        }
+    }
+    return false
+}
+
+func (e *Editor) handleFindMode(ev *tcell.EventKey) bool {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil {
+        return true
+    }
+
+    switch ev.Key() {
+    case tcell.KeyEscape:
+        e.mode = ModeNormal
+        e.searchMatches = nil
+        tab.clearSelection()
+        *tab.cursor = e.searchOrigin
+        e.setStatusMsg("Search cancelled")
+    case tcell.KeyEnter:
+        e.mode = ModeNormal
+        e.findQuery = e.inputBuffer
+        matches, current := e.searchMatches, e.searchCurrent
+        e.searchMatches = nil
+        if e.findQuery == "" {
+            e.setStatusMsg("No search query entered")
+            return true
+        }
+        e.addSearchHistory(e.findQuery)
+        if current < 0 {
+            e.actionFailed = true
+            e.setStatusMsg(fmt.Sprintf("'%s' not found in document", e.findQuery))
+            return true
+        }
+        // A macro must not loop forever over wrapped matches.
+        if e.playingMacro && matches[current].before(e.searchOrigin.Row, e.searchOrigin.Col) {
+            e.actionFailed = true
+            tab.clearSelection()
+            *tab.cursor = e.searchOrigin
+            e.setStatusMsg(fmt.Sprintf("'%s' not found before end of document", e.findQuery))
+            return true
+        }
+        e.setStatusMsg(fmt.Sprintf("Found '%s': match %d of %d", e.findQuery, current+1, len(matches)))
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if len(e.inputBuffer) > 0 {
+            e.inputBuffer = e.inputBuffer[:len(e.inputBuffer)-1]
+        }
+        e.updateIncrementalSearch()
+    case tcell.KeyUp, tcell.KeyDown:
+        dir := -1
+        if ev.Key() == tcell.KeyDown {
+            dir = 1
+        }
+        if e.browseHistory(dir) {
+            e.updateIncrementalSearch()
+        }
+    case tcell.KeyRune:
+        if ev.Modifiers()&tcell.ModAlt != 0 {
+            e.searchOpts.toggle(ev.Rune())
+        } else {
+            e.inputBuffer += string(ev.Rune())
+        }
+        e.updateIncrementalSearch()
+    }
+    return true
+}
+
+// findText moves to the next match of e.findQuery after the cursor, or
+// the previous one before it, wrapping around the buffer.
+func (e *Editor) findText(backward bool) {
+    tab := e.tabManager.GetActiveTab()
+    if tab == nil || tab.buffer == nil || tab.cursor == nil {
+        return
+    }
+
+    if e.findQuery == "" {
+        e.setStatusMsg("No previous search. Press Ctrl+F to search")
+        return
+    }
+
+    re, err := compileSearch(e.findQuery, e.searchOpts)
+    if err != nil {
+        e.actionFailed = true
+        e.setStatusMsg(err.Error())
+        return
+    }
+
+    matches := findMatches(tab.buffer, re, e.searchOpts.Multiline)
+    if len(matches) == 0 {
+        e.actionFailed = true
+        e.setStatusMsg(fmt.Sprintf("'%s' not found in document", e.findQuery))
+        return
+    }
+
+    row, col := tab.cursor.Row, tab.cursor.Col
+    var next int
+    wrapped := false
+    if backward {
+        next = sort.Search(len(matches), func(i int) bool { return !matches[i].before(row, col) }) - 1
+        if next < 0 {
+            next, wrapped = len(matches)-1, true
+        }
+    } else {
+        next = sort.Search(len(matches), func(i int) bool { return matches[i].after(row, col) })
+        if next == len(matches) {
+            next, wrapped = 0, true
+        }
+    }
+
+    // A macro must not loop forever over wrapped matches.
+    if wrapped && e.playingMacro {
+        e.actionFailed = true
+        e.setStatusMsg(fmt.Sprintf("'%s' not found before the end of the document", e.findQuery))
+        return
+    }
+
+    m := matches[next]
+    selectMatch(tab, m)
+    e.ensureCursorValid(tab)
+    msg := fmt.Sprintf("Found '%s': match %d of %d", e.findQuery, next+1, len(matches))
+    if wrapped {
+        msg += " (wrapped)"
+    }
+    e.setStatusMsg(msg)
+}
+
+func searchHistoryPath() string {
+    return filepath.Join(configDir(), "search_history.json")
+}
+
+func loadSearchHistory() []string {
+    var history []string
+    data, err := os.ReadFile(searchHistoryPath())
+    if err != nil {
+        return nil
+    }
+    if err := json.Unmarshal(data, &history); err != nil {
+        return nil
+    }
+    return history
+}
+
+func saveSearchHistory(history []string) error {
+    data, err := json.MarshalIndent(history, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(configDir(), 0755); err != nil {
+        return err
+    }
+    return os.WriteFile(searchHistoryPath(), data, 0644)
+}
+
+// addSearchHistory moves query to the end of the history and saves it.
+func (e *Editor) addSearchHistory(query string) {
+    if query == "" {
+        return
+    }
+    for i, q := range e.searchHistory {
+        if q == query {
+            e.searchHistory = append(e.searchHistory[:i], e.searchHistory[i+1:]...)
+            break
+        }
+    }
+    e.searchHistory = append(e.searchHistory, query)
+    if len(e.searchHistory) > maxSearchHistory {
+        e.searchHistory = e.searchHistory[len(e.searchHistory)-maxSearchHistory:]
+    }
+    if err := saveSearchHistory(e.searchHistory); err != nil {
+        e.setStatusMsg(fmt.Sprintf("Failed to save search history: %v", err))
+    }
+}
+
+// browseHistory puts an older (dir -1) or newer (dir 1) query into the
+// prompt. Going past the newest entry brings back what was typed.
+func (e *Editor) browseHistory(dir int) bool {
+    if e.historyIndex == len(e.searchHistory) {
+        e.historyDraft = e.inputBuffer
+    }
+    index := e.historyIndex + dir
+    if index < 0 || index > len(e.searchHistory) {
+        return false
+    }
+    e.historyIndex = index
+    if index == len(e.searchHistory) {
+        e.inputBuffer = e.historyDraft
+    } else {
+        e.inputBuffer = e.searchHistory[index]
+    }
+    return true
+}
@@ -0,0 +1,97 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "github.com/gdamore/tcell/v2"
+)
+
+func (t *Tab) startSelection() {
+    if !t.selActive {
+        t.selActive = true
+        t.selAnchor = *t.cursor
+    }
+}
+
+func (t *Tab) clearSelection() {
+    t.selActive = false
+}
+
+// selectionRange returns the selection ordered from start to end. It
+// reports false when nothing is selected.
+func (t *Tab) selectionRange() (startRow, startCol, endRow, endCol int, ok bool) {
+    if !t.selActive {
+        return 0, 0, 0, 0, false
+    }
+
+    a, c := t.selAnchor, *t.cursor
+    if a.Row > c.Row || (a.Row == c.Row && a.Col > c.Col) {
+        a, c = c, a
+    }
+    if a.Row == c.Row && a.Col == c.Col {
+        return 0, 0, 0, 0, false
+    }
+    return a.Row, a.Col, c.Row, c.Col, true
+}
+
+// selectedLines returns the rows [start, end) covered by the selection,
+// or just the cursor row when nothing is selected. A selection ending at
+// column 0 does not include that last line.
+func (t *Tab) selectedLines() (start, end int) {
+    startRow, _, endRow, endCol, ok := t.selectionRange()
+    if !ok {
+        return t.cursor.Row, t.cursor.Row + 1
+    }
+    if endCol == 0 && endRow > startRow {
+        endRow--
+    }
+    return startRow, endRow + 1
+}
+
+func (t *Tab) isSelected(row, col int) bool {
+    startRow, startCol, endRow, endCol, ok := t.selectionRange()
+    if !ok || row < startRow || row > endRow {
+        return false
+    }
+    if row == startRow && col < startCol {
+        return false
+    }
+    if row == endRow && col >= endCol {
+        return false
+    }
+    return true
+}
+
+func (t *Tab) selectedText() string {
+    startRow, startCol, endRow, endCol, ok := t.selectionRange()
+    if !ok {
+        return ""
+    }
+    return t.buffer.GetRange(startRow, startCol, endRow, endCol)
+}
+
+// deleteSelection removes the selected text without saving an undo
+// state. It reports whether anything was deleted.
+func (t *Tab) deleteSelection() bool {
+    startRow, startCol, endRow, endCol, ok := t.selectionRange()
+    t.clearSelection()
+    if !ok {
+        return false
+    }
+
+    t.buffer.DeleteRange(startRow, startCol, endRow, endCol)
+    t.cursor.Row = startRow
+    t.cursor.Col = startCol
+    return true
+}
+
+func isMovementKey(key tcell.Key) bool {
+    switch key {
+    case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight,
+        tcell.KeyHome, tcell.KeyEnd, tcell.KeyPgUp, tcell.KeyPgDn:
+        return true
+    }
+    return false
+}
@@ -0,0 +1,449 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+// Snippet bodies use numbered tab stops: $1, ${1:default}, and $0 for
+// the final cursor position. A number used more than once is mirrored.
+// A literal dollar sign is written as \$, and a tab as \t (expanded to
+// one indentation level).
+type Snippet struct {
+    Body        string `json:"-"`
+    Description string `json:"description,omitempty"`
+}
+
+func (s *Snippet) UnmarshalJSON(data []byte) error {
+    var raw struct {
+        Body        json.RawMessage `json:"body"`
+        Description string          `json:"description"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    s.Description = raw.Description
+
+    var lines []string
+    if err := json.Unmarshal(raw.Body, &lines); err == nil {
+        s.Body = strings.Join(lines, "\n")
+        return nil
+    }
+    return json.Unmarshal(raw.Body, &s.Body)
+}
+
+type snippetStop struct {
+    number int
+    row    int
+    col    int
+    length int
+}
+
+type snippetSession struct {
+    stops   []*snippetStop
+    order   []int
+    current int
+    fresh   bool
+}
+
+func builtinSnippets() map[string]map[string]*Snippet {
+    return map[string]map[string]*Snippet{
+        "go": {
+            "iferr": {Body: "if err != nil {\n\treturn ${1:err}\n}\n$0"},
+            "fn":    {Body: "func ${1:name}(${2}) ${3:error} {\n\t$0\n}"},
+            "for":   {Body: "for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}"},
+            "main":  {Body: "func main() {\n\t$0\n}"},
+            "test":  {Body: "func Test${1:Name}(t *testing.T) {\n\t$0\n}"},
+        },
+        "python": {
+            "def":  {Body: "def ${1:name}(${2}):\n\t${0:pass}"},
+            "main": {Body: "if __name__ == \"__main__\":\n\t${0:main()}"},
+        },
+        "shell": {
+            "if":  {Body: "if [ ${1:condition} ]; then\n\t$0\nfi"},
+            "for": {Body: "for ${1:item} in ${2:list}; do\n\t$0\ndone"},
+        },
+    }
+}
+
+// loadSnippets merges the built-in snippets with the files in the
+// snippets config directory: <language>.json for one language and
+// global.json for all of them.
+func loadSnippets() map[string]map[string]*Snippet {
+    snippets := builtinSnippets()
+
+    dir := filepath.Join(configDir(), "snippets")
+    files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+    if err != nil {
+        return snippets
+    }
+    for _, file := range files {
+        data, err := os.ReadFile(file)
+        if err != nil {
+            continue
+        }
+        var defs map[string]*Snippet
+        if err := json.Unmarshal(data, &defs); err != nil {
+            continue
+        }
+        lang := strings.TrimSuffix(filepath.Base(file), ".json")
+        if snippets[lang] == nil {
+            snippets[lang] = make(map[string]*Snippet)
+        }
+        for trigger, snip := range defs {
+            snippets[lang][trigger] = snip
+        }
+    }
+    return snippets
+}
+
+func (e *Editor) findSnippet(lang *Language, trigger string) *Snippet {
+    if snip, ok := e.snippets[lang.Name][trigger]; ok {
+        return snip
+    }
+    return e.snippets["global"][trigger]
+}
+
+// expandSnippetBody lays out the body starting at (row, col). Following
+// lines get indent, and \t becomes the indentation unit.
+func expandSnippetBody(body, indent, unit string, row, col int) (string, []*snippetStop) {
+    defaults := make(map[int]string)
+    scanSnippet(body, func(number int, def string, hasDefault bool) {
+        if hasDefault && defaults[number] == "" {
+            defaults[number] = def
+        }
+    }, nil)
+
+    var out strings.Builder
+    var stops []*snippetStop
+    scanSnippet(body, nil, func(text string, number int, isStop bool) {
+        if !isStop {
+            for _, r := range text {
+                switch r {
+                case '\n':
+                    out.WriteString("\n" + indent)
+                    row++
+                    col = len(indent)
+                case '\t':
+                    out.WriteString(unit)
+                    col += len(unit)
+                default:
+                    out.WriteRune(r)
+                    col += len(string(r))
+                }
+            }
+            return
+        }
+        def := defaults[number]
+        stops = append(stops, &snippetStop{number: number, row: row, col: col, length: len(def)})
+        out.WriteString(def)
+        col += len(def)
+    })
+
+    hasFinal := false
+    for _, s := range stops {
+        if s.number == 0 {
+            hasFinal = true
+        }
+    }
+    if !hasFinal {
+        stops = append(stops, &snippetStop{number: 0, row: row, col: col})
+    }
+    return out.String(), stops
+}
+
+// scanSnippet walks a snippet body. onDefault is called for every tab
+// stop with its default text, emit for every literal run and tab stop.
+func scanSnippet(body string, onDefault func(number int, def string, hasDefault bool), emit func(text string, number int, isStop bool)) {
+    var literal strings.Builder
+    flush := func() {
+        if literal.Len() > 0 && emit != nil {
+            emit(literal.String(), 0, false)
+        }
+        literal.Reset()
+    }
+
+    for i := 0; i < len(body); i++ {
+        c := body[i]
+        if c == '\\' && i+1 < len(body) {
+            switch body[i+1] {
+            case '$', '\\', '}':
+                literal.WriteByte(body[i+1])
+                i++
+                continue
+            case 't':
+                literal.WriteByte('\t')
+                i++
+                continue
+            }
+        }
+        if c != '$' || i+1 >= len(body) {
+            literal.WriteByte(c)
+            continue
+        }
+
+        number, def, hasDefault, next := -1, "", false, i+1
+        if body[next] == '{' {
+            j := next + 1
+            for j < len(body) && body[j] >= '0' && body[j] <= '9' {
+                j++
+            }
+            end := strings.IndexByte(body[j:], '}')
+            if j > next+1 && end >= 0 && (body[j] == '}' || body[j] == ':') {
+                fmt.Sscanf(body[next+1:j], "%d", &number)
+                if body[j] == ':' {
+                    def, hasDefault = body[j+1:j+end], true
+                }
+                next = j + end + 1
+            }
+        } else {
+            j := next
+            for j < len(body) && body[j] >= '0' && body[j] <= '9' {
+                j++
+            }
+            if j > next {
+                fmt.Sscanf(body[next:j], "%d", &number)
+                next = j
+            }
+        }
+
+        if number < 0 {
+            literal.WriteByte(c)
+            continue
+        }
+        flush()
+        if onDefault != nil {
+            onDefault(number, def, hasDefault)
+        }
+        if emit != nil {
+            emit("", number, true)
+        }
+        i = next - 1
+    }
+    flush()
+}
+
+// expandSnippet replaces the trigger word before the cursor with its
+// snippet and starts tab-stop navigation.
+func (e *Editor) expandSnippet(tab *Tab) {
+    lang := e.languageFor(tab)
+    row := tab.cursor.Row
+    line := tab.buffer.GetLine(row)
+    col := clampCol(line, tab.cursor.Col)
+    start := lang.wordStart(line, col)
+    trigger := line[start:col]
+
+    snip := e.findSnippet(lang, trigger)
+    if trigger == "" || snip == nil {
+        e.setStatusMsg(fmt.Sprintf("No snippet for '%s'", trigger))
+        return
+    }
+
+    tab.clearSelection()
+    text, stops := expandSnippetBody(snip.Body, leadingWhitespace(line), lang.IndentUnit, row, start)
+    tab.buffer.SetLine(row, line[:start]+line[col:])
+    tab.buffer.InsertText(row, start, text)
+
+    numbers := make(map[int]bool)
+    for _, s := range stops {
+        numbers[s.number] = true
+    }
+    order := make([]int, 0, len(numbers))
+    for n := range numbers {
+        if n != 0 {
+            order = append(order, n)
+        }
+    }
+    sort.Ints(order)
+    order = append(order, 0)
+
+    tab.snippet = &snippetSession{stops: stops, order: order}
+    e.activateSnippetStop(tab, 0)
+    tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+    e.quitAttempts = 0
+}
+
+func (e *Editor) activateSnippetStop(tab *Tab, index int) {
+    s := tab.snippet
+    s.current = index
+    s.fresh = true
+
+    stop := s.primaryStop()
+    tab.cursor.Row = stop.row
+    tab.cursor.Col = stop.col + stop.length
+
+    if s.order[index] == 0 {
+        tab.snippet = nil
+        e.setStatusMsg("Snippet complete")
+        return
+    }
+    e.setStatusMsg(fmt.Sprintf("Snippet: field %d of %d (Tab/Shift+Tab to move, Esc to finish)", index+1, len(s.order)-1))
+}
+
+func (s *snippetSession) primaryStop() *snippetStop {
+    for _, stop := range s.stops {
+        if stop.number == s.order[s.current] {
+            return stop
+        }
+    }
+    return s.stops[len(s.stops)-1]
+}
+
+// stopAtCursor returns the occurrence of the current field containing
+// the cursor, or nil when the cursor left the field.
+func (s *snippetSession) stopAtCursor(c *Cursor) *snippetStop {
+    for _, stop := range s.stops {
+        if stop.number == s.order[s.current] && stop.row == c.Row && c.Col >= stop.col && c.Col <= stop.col+stop.length {
+            return stop
+        }
+    }
+    return nil
+}
+
+func (s *snippetSession) isCurrentField(row, col int) bool {
+    for _, stop := range s.stops {
+        if stop.number == s.order[s.current] && stop.row == row && col >= stop.col && col < stop.col+stop.length {
+            return true
+        }
+    }
+    return false
+}
+
+// setFieldText rewrites every occurrence of the current field and shifts
+// the stops that follow on the same line.
+func (e *Editor) setFieldText(tab *Tab, text string, offset int) {
+    s := tab.snippet
+    number := s.order[s.current]
+    cursorStop := s.stopAtCursor(tab.cursor)
+
+    for _, stop := range s.stops {
+        if stop.number != number {
+            continue
+        }
+        line := tab.buffer.GetLine(stop.row)
+        tab.buffer.SetLine(stop.row, line[:stop.col]+text+line[stop.col+stop.length:])
+
+        delta := len(text) - stop.length
+        for _, other := range s.stops {
+            if other != stop && other.row == stop.row && other.col > stop.col {
+                other.col += delta
+            }
+        }
+        stop.length = len(text)
+    }
+
+    if cursorStop != nil {
+        tab.cursor.Row = cursorStop.row
+        tab.cursor.Col = cursorStop.col + offset
+    }
+    s.fresh = false
+}
+
+// handleSnippetKey processes keys while a snippet is being filled in.
+// It reports whether the key was consumed; any key that leaves the
+// current field ends the session and is handled normally.
+func (e *Editor) handleSnippetKey(tab *Tab, ev *tcell.EventKey) bool {
+    s := tab.snippet
+    switch ev.Key() {
+    case tcell.KeyTab:
+        e.activateSnippetStop(tab, s.current+1)
+        return true
+    case tcell.KeyBacktab:
+        if s.current > 0 {
+            e.activateSnippetStop(tab, s.current-1)
+        }
+        return true
+    case tcell.KeyEscape:
+        tab.snippet = nil
+        e.setStatusMsg("Snippet finished")
+        return true
+    }
+
+    stop := s.stopAtCursor(tab.cursor)
+    if stop == nil {
+        tab.snippet = nil
+        return false
+    }
+
+    line := tab.buffer.GetLine(stop.row)
+    field := line[stop.col : stop.col+stop.length]
+    offset := tab.cursor.Col - stop.col
+
+    switch ev.Key() {
+    case tcell.KeyRune:
+        if ev.Modifiers()&tcell.ModAlt != 0 {
+            break
+        }
+        if s.fresh {
+            field, offset = "", 0
+        }
+        r := string(ev.Rune())
+        e.setFieldText(tab, field[:offset]+r+field[offset:], offset+len(r))
+        tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+        return true
+    case tcell.KeyBackspace, tcell.KeyBackspace2:
+        if s.fresh {
+            e.setFieldText(tab, "", 0)
+        } else if offset > 0 {
+            _, size := utf8.DecodeLastRuneInString(field[:offset])
+            e.setFieldText(tab, field[:offset-size]+field[offset:], offset-size)
+        } else {
+            break
+        }
+        tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+        return true
+    case tcell.KeyDelete:
+        if s.fresh {
+            e.setFieldText(tab, "", 0)
+        } else if offset < len(field) {
+            _, size := utf8.DecodeRuneInString(field[offset:])
+            e.setFieldText(tab, field[:offset]+field[offset+size:], offset)
+        } else {
+            break
+        }
+        tab.buffer.SaveState(tab.cursor.Row, tab.cursor.Col)
+        return true
+    case tcell.KeyLeft:
+        if offset > 0 && ev.Modifiers() == tcell.ModNone {
+            _, size := utf8.DecodeLastRuneInString(field[:offset])
+            tab.cursor.Col -= size
+            s.fresh = false
+            return true
+        }
+    case tcell.KeyRight:
+        if offset < len(field) && ev.Modifiers() == tcell.ModNone {
+            _, size := utf8.DecodeRuneInString(field[offset:])
+            tab.cursor.Col += size
+            s.fresh = false
+            return true
+        }
+    }
+
+    tab.snippet = nil
+    return false
+}
+
+func (e *Editor) snippetTriggers(lang *Language) []string {
+    var triggers []string
+    for trigger := range e.snippets[lang.Name] {
+        triggers = append(triggers, trigger)
+    }
+    for trigger := range e.snippets["global"] {
+        if _, ok := e.snippets[lang.Name][trigger]; !ok {
+            triggers = append(triggers, trigger)
+        }
+    }
+    sort.Strings(triggers)
+    return triggers
+}
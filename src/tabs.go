@@ -14,10 +14,16 @@ type TabManager struct {
 }
 
 type Tab struct {
-    buffer    *Buffer
-    cursor    *Cursor
-    offsetRow int
-    offsetCol int
+    buffer     *Buffer
+    cursor     *Cursor
+    offsetRow  int
+    offsetCol  int
+    offsetSub  int
+    selActive  bool
+    selAnchor  Cursor
+    autoClosed int
+    snippet    *snippetSession
+    folds      []foldRange
 }
 
 func NewTabManager() *TabManager {
@@ -28,7 +34,7 @@ func NewTabManager() *TabManager {
 }
 
 func (tm *TabManager) AddTab(filename string) error {
-    buffer, err := NewBuffer(filename)
+    buffer, err := NewBuffer(filename, findEditorConfig(filename))
     if err != nil {
         return err
     }
@@ -46,6 +52,33 @@ func (tm *TabManager) AddTab(filename string) error {
     return nil
 }
 
+// FindFile returns the index of the tab showing filename, or -1.
+func (tm *TabManager) FindFile(filename string) int {
+    target, err := filepath.Abs(filename)
+    if err != nil {
+        return -1
+    }
+    for i, tab := range tm.tabs {
+        if tab == nil || tab.buffer == nil || tab.buffer.filename == "" {
+            continue
+        }
+        if path, err := filepath.Abs(tab.buffer.filename); err == nil && path == target {
+            return i
+        }
+    }
+    return -1
+}
+
+// OpenFile switches to the tab showing filename, or opens it in a new
+// tab.
+func (tm *TabManager) OpenFile(filename string) error {
+    if i := tm.FindFile(filename); i >= 0 {
+        tm.activeTab = i
+        return nil
+    }
+    return tm.AddTab(filename)
+}
+
 func (tm *TabManager) GetActiveTab() *Tab {
     if tm.activeTab < 0 || tm.activeTab >= len(tm.tabs) {
         return nil
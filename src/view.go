@@ -0,0 +1,360 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "fmt"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+const (
+    defaultTabWidth     = 4
+    defaultScrollMargin = 5
+    wrapIndicator       = '↪'
+    foldIndicator       = '⋯'
+    scrollLeftMark      = '«'
+    scrollRightMark     = '»'
+)
+
+// screenRow is the part of a buffer line shown on one screen row.
+// start and end are byte offsets; cont marks a soft-wrapped continuation.
+type screenRow struct {
+    row    int
+    start  int
+    end    int
+    cont   bool
+    folded int
+}
+
+func (e *Editor) tabWidth(tab *Tab) int {
+    if ec := tab.buffer.editorConfig; ec != nil && ec.TabWidth > 0 {
+        return ec.TabWidth
+    }
+    return defaultTabWidth
+}
+
+func cellWidth(r rune, x, tabWidth int) int {
+    if r == '\t' {
+        return tabWidth - x%tabWidth
+    }
+    return 1
+}
+
+// displayCol returns the screen column of byte offset col in text.
+func displayCol(text string, col, tabWidth int) int {
+    x := 0
+    for i, r := range text {
+        if i >= col {
+            break
+        }
+        x += cellWidth(r, x, tabWidth)
+    }
+    return x
+}
+
+// byteCol returns the byte offset of the character shown at screen
+// column x, or len(text) when x lies past the end.
+func byteCol(text string, x, tabWidth int) int {
+    cx := 0
+    for i, r := range text {
+        w := cellWidth(r, cx, tabWidth)
+        if cx+w > x {
+            return i
+        }
+        cx += w
+    }
+    return len(text)
+}
+
+// wrapLine splits line into screen rows of at most width cells, breaking
+// after the last space where possible. Continuation rows lose one cell to
+// the wrap indicator. The result holds the start offset of every row.
+func wrapLine(line string, width, tabWidth int) []int {
+    starts := []int{0}
+    if width < 2 {
+        return starts
+    }
+
+    start, x, space := 0, 0, -1
+    avail := width
+    for i := 0; i < len(line); {
+        r, size := utf8.DecodeRuneInString(line[i:])
+        w := cellWidth(r, x, tabWidth)
+        if x+w > avail && i > start {
+            if space > start {
+                i = space
+            }
+            start = i
+            starts = append(starts, start)
+            x, space = 0, -1
+            avail = width - 1
+            continue
+        }
+        x += w
+        i += size
+        if r == ' ' {
+            space = i
+        }
+    }
+    return starts
+}
+
+// textWidth is the number of columns available for text, which is
+// less than the screen width while the outline panel or the diagnostics
+// gutter is shown.
+func (e *Editor) textWidth() int {
+    return e.width - e.outlineWidth() - e.gutterWidth()
+}
+
+// wrapStarts returns the screen row starts of a line. Without soft wrap,
+// and for folded lines, a line is a single screen row.
+func (e *Editor) wrapStarts(tab *Tab, row int) []int {
+    if !e.softWrap || tab.closedFoldAt(row) != nil {
+        return []int{0}
+    }
+    return wrapLine(tab.buffer.GetLine(row), e.textWidth(), e.tabWidth(tab))
+}
+
+// cursorSegment returns the index of the screen row of line row that
+// holds byte offset col.
+func cursorSegment(starts []int, col int) int {
+    seg := 0
+    for i, s := range starts {
+        if s <= col {
+            seg = i
+        }
+    }
+    return seg
+}
+
+func (e *Editor) segmentRow(tab *Tab, row, seg int) screenRow {
+    starts := e.wrapStarts(tab, row)
+    end := len(tab.buffer.GetLine(row))
+    if seg+1 < len(starts) {
+        end = starts[seg+1]
+    }
+    sr := screenRow{row: row, start: starts[seg], end: end, cont: seg > 0}
+    if f := tab.closedFoldAt(row); f != nil {
+        sr.folded = f.end - f.start
+    }
+    return sr
+}
+
+// scrollVertical keeps the cursor's screen row inside the window,
+// counting wrapped rows and skipping folded lines.
+func (e *Editor) scrollVertical(tab *Tab) {
+    if tab.offsetRow >= tab.buffer.LineCount() {
+        tab.offsetRow = tab.buffer.LineCount() - 1
+        tab.offsetSub = 0
+    }
+    if tab.offsetRow < 0 {
+        tab.offsetRow = 0
+    }
+    if f := tab.hidingFold(tab.offsetRow); f != nil {
+        tab.offsetRow, tab.offsetSub = f.start, 0
+    }
+    if n := len(e.wrapStarts(tab, tab.offsetRow)); tab.offsetSub >= n {
+        tab.offsetSub = n - 1
+    }
+
+    row := tab.cursor.Row
+    seg := cursorSegment(e.wrapStarts(tab, row), tab.cursor.Col)
+    if row < tab.offsetRow || row == tab.offsetRow && seg < tab.offsetSub {
+        tab.offsetRow, tab.offsetSub = row, seg
+        return
+    }
+
+    // Walk back from the cursor; if the top is reached within one
+    // screen the cursor is visible.
+    for n := 1; n < e.height; n++ {
+        if row == tab.offsetRow && seg == tab.offsetSub {
+            return
+        }
+        if seg > 0 {
+            seg--
+        } else if row = tab.nextVisibleRow(row, -1); row < 0 {
+            return
+        } else {
+            seg = len(e.wrapStarts(tab, row)) - 1
+        }
+    }
+    if row > tab.offsetRow || row == tab.offsetRow && seg > tab.offsetSub {
+        tab.offsetRow, tab.offsetSub = row, seg
+    }
+}
+
+// visibleRows lays out the screen rows from the top of the window.
+func (e *Editor) visibleRows(tab *Tab) []screenRow {
+    rows := make([]screenRow, 0, e.height)
+    for row := tab.offsetRow; row >= 0 && row < tab.buffer.LineCount() && len(rows) < e.height; row = tab.nextVisibleRow(row, 1) {
+        starts := e.wrapStarts(tab, row)
+        for seg := range starts {
+            if row == tab.offsetRow && seg < tab.offsetSub {
+                continue
+            }
+            if len(rows) == e.height {
+                break
+            }
+            rows = append(rows, e.segmentRow(tab, row, seg))
+        }
+    }
+    return rows
+}
+
+// cursorScreenPos returns the cursor's position relative to the text
+// area.
+func (e *Editor) cursorScreenPos(tab *Tab, rows []screenRow) (int, int) {
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    tw := e.tabWidth(tab)
+    starts := e.wrapStarts(tab, tab.cursor.Row)
+    start := starts[cursorSegment(starts, tab.cursor.Col)]
+    for y, sr := range rows {
+        if sr.row == tab.cursor.Row && sr.start == start {
+            x := displayCol(line[start:], tab.cursor.Col-start, tw) - tab.offsetCol
+            if sr.cont {
+                x++
+            }
+            return x, y
+        }
+    }
+    return 0, 0
+}
+
+func (e *Editor) drawScreenRow(tab *Tab, sr screenRow, y int) {
+    gutter := e.gutterWidth()
+    line := tab.buffer.GetLine(sr.row)
+    tw := e.tabWidth(tab)
+    markStyle := tcell.StyleDefault.Foreground(tcell.ColorBlue)
+    left := 0
+    if sr.cont {
+        e.screen.SetContent(gutter, y, wrapIndicator, nil, markStyle)
+        left = -1
+    } else if !e.softWrap {
+        left = tab.offsetCol
+    }
+
+    cx := 0
+    for i, r := range line[sr.start:sr.end] {
+        col := sr.start + i
+        w := cellWidth(r, cx, tw)
+        style := e.cellStyle(tab, sr.row, col)
+        if r == '\t' {
+            r = ' '
+        }
+        for ; w > 0; w-- {
+            if x := cx - left; x >= 0 && x < e.textWidth() {
+                e.screen.SetContent(gutter+x, y, r, nil, style)
+            }
+            r = ' '
+            cx++
+        }
+    }
+
+    if left > 0 && cx > 0 {
+        e.screen.SetContent(gutter, y, scrollLeftMark, nil, markStyle)
+    }
+    if !e.softWrap && cx-left > e.textWidth() {
+        e.screen.SetContent(gutter+e.textWidth()-1, y, scrollRightMark, nil, markStyle)
+    }
+
+    if sr.folded > 0 {
+        x := cx - left + 1
+        if x < 0 {
+            x = 0
+        }
+        for _, r := range fmt.Sprintf("%c %d lines", foldIndicator, sr.folded) {
+            if x >= e.textWidth() {
+                break
+            }
+            e.screen.SetContent(gutter+x, y, r, nil, markStyle.Reverse(true))
+            x++
+        }
+    }
+}
+
+func (e *Editor) scrollMargin() int {
+    margin := e.config.ScrollMargin
+    if margin <= 0 {
+        margin = defaultScrollMargin
+    }
+    if margin > (e.textWidth()-1)/2 {
+        margin = (e.textWidth() - 1) / 2
+    }
+    return margin
+}
+
+// scrollHorizontal adjusts offsetCol so the cursor stays at least the
+// side margin away from either edge.
+func (e *Editor) scrollHorizontal(tab *Tab) {
+    if e.softWrap {
+        tab.offsetCol = 0
+        return
+    }
+
+    x := displayCol(tab.buffer.GetLine(tab.cursor.Row), tab.cursor.Col, e.tabWidth(tab))
+    margin := e.scrollMargin()
+    if x < tab.offsetCol+margin {
+        tab.offsetCol = x - margin
+    }
+    if x > tab.offsetCol+e.textWidth()-1-margin {
+        tab.offsetCol = x - (e.textWidth() - 1 - margin)
+    }
+    if tab.offsetCol < 0 {
+        tab.offsetCol = 0
+    }
+}
+
+func (e *Editor) toggleSoftWrap() {
+    e.softWrap = !e.softWrap
+    if e.softWrap {
+        e.setStatusMsg("Soft wrap on")
+    } else {
+        e.setStatusMsg("Soft wrap off")
+    }
+}
+
+// moveScreenRow moves the cursor dir screen rows up or down, keeping its
+// screen column. Folded lines are skipped.
+func (e *Editor) moveScreenRow(tab *Tab, dir int) {
+    tw := e.tabWidth(tab)
+    row := tab.cursor.Row
+    seg := cursorSegment(e.wrapStarts(tab, row), tab.cursor.Col)
+    cur := e.segmentRow(tab, row, seg)
+    line := tab.buffer.GetLine(row)
+    x := displayCol(line[cur.start:], tab.cursor.Col-cur.start, tw)
+    if cur.cont {
+        x++
+    }
+
+    seg += dir
+    if seg < 0 || seg >= len(e.wrapStarts(tab, row)) {
+        if row = tab.nextVisibleRow(row, dir); row < 0 {
+            return
+        }
+        seg = 0
+        if dir < 0 {
+            seg = len(e.wrapStarts(tab, row)) - 1
+        }
+    }
+
+    target := e.segmentRow(tab, row, seg)
+    if target.cont {
+        x--
+    }
+    if x < 0 {
+        x = 0
+    }
+    text := tab.buffer.GetLine(row)[target.start:target.end]
+    col := target.start + byteCol(text, x, tw)
+    // A wrapped row's end is the next row's start; stay on this row.
+    if col == target.end && target.end < len(tab.buffer.GetLine(row)) {
+        _, size := utf8.DecodeLastRuneInString(text)
+        col -= size
+    }
+    tab.cursor.Row = row
+    tab.cursor.Col = col
+}
@@ -0,0 +1,161 @@
+// GoEdit v2.0
+// Copyright © Prof. Dr. Michael Stal, 2025
+// All rights reserved.
+
+package main
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+const (
+    classSpace = iota
+    classWord
+    classPunct
+)
+
+func (l *Language) isWordChar(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) ||
+        strings.ContainsRune(l.WordChars, r)
+}
+
+func (l *Language) charClass(r rune) int {
+    switch {
+    case unicode.IsSpace(r):
+        return classSpace
+    case l.isWordChar(r):
+        return classWord
+    default:
+        return classPunct
+    }
+}
+
+// wordEnd returns the column after the next word starting at col: blanks
+// are skipped, then a run of word or punctuation characters.
+func (l *Language) wordEnd(line string, col int) int {
+    col = clampCol(line, col)
+    for col < len(line) {
+        r, size := utf8.DecodeRuneInString(line[col:])
+        if l.charClass(r) != classSpace {
+            break
+        }
+        col += size
+    }
+    if col >= len(line) {
+        return col
+    }
+
+    r, _ := utf8.DecodeRuneInString(line[col:])
+    class := l.charClass(r)
+    for col < len(line) {
+        r, size := utf8.DecodeRuneInString(line[col:])
+        if l.charClass(r) != class {
+            break
+        }
+        col += size
+    }
+    return col
+}
+
+// wordStart is the mirror of wordEnd and returns the column where the
+// word before col begins.
+func (l *Language) wordStart(line string, col int) int {
+    col = clampCol(line, col)
+    for col > 0 {
+        r, size := utf8.DecodeLastRuneInString(line[:col])
+        if l.charClass(r) != classSpace {
+            break
+        }
+        col -= size
+    }
+    if col == 0 {
+        return 0
+    }
+
+    r, _ := utf8.DecodeLastRuneInString(line[:col])
+    class := l.charClass(r)
+    for col > 0 {
+        r, size := utf8.DecodeLastRuneInString(line[:col])
+        if l.charClass(r) != class {
+            break
+        }
+        col -= size
+    }
+    return col
+}
+
+func (e *Editor) wordLeft(tab *Tab) {
+    if tab.cursor.Col == 0 {
+        if tab.cursor.Row > 0 {
+            tab.cursor.Row--
+            tab.cursor.Col = len(tab.buffer.GetLine(tab.cursor.Row))
+        }
+        return
+    }
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    tab.cursor.Col = e.languageFor(tab).wordStart(line, tab.cursor.Col)
+}
+
+func (e *Editor) wordRight(tab *Tab) {
+    line := tab.buffer.GetLine(tab.cursor.Row)
+    if tab.cursor.Col >= len(line) {
+        if tab.cursor.Row < tab.buffer.LineCount()-1 {
+            tab.cursor.Row++
+            tab.cursor.Col = 0
+        }
+        return
+    }
+    tab.cursor.Col = e.languageFor(tab).wordEnd(line, tab.cursor.Col)
+}
+
+func (e *Editor) deleteWordBackward(tab *Tab) {
+    row, col := tab.cursor.Row, tab.cursor.Col
+    e.wordLeft(tab)
+    tab.buffer.DeleteRange(tab.cursor.Row, tab.cursor.Col, row, col)
+}
+
+func (e *Editor) deleteWordForward(tab *Tab) {
+    row, col := tab.cursor.Row, tab.cursor.Col
+    e.wordRight(tab)
+    tab.buffer.DeleteRange(row, col, tab.cursor.Row, tab.cursor.Col)
+    tab.cursor.Row = row
+    tab.cursor.Col = col
+}
+
+// paragraphUp moves to the blank line above the current paragraph, or
+// to the top of the buffer.
+func (e *Editor) paragraphUp(tab *Tab) {
+    row := tab.cursor.Row
+    for row > 0 && isBlank(tab.buffer.GetLine(row)) {
+        row--
+    }
+    for row > 0 && !isBlank(tab.buffer.GetLine(row)) {
+        row--
+    }
+    if row < 0 {
+        row = 0
+    }
+    tab.cursor.Row = row
+    tab.cursor.Col = 0
+}
+
+func (e *Editor) paragraphDown(tab *Tab) {
+    last := tab.buffer.LineCount() - 1
+    row := tab.cursor.Row
+    for row < last && isBlank(tab.buffer.GetLine(row)) {
+        row++
+    }
+    for row < last && !isBlank(tab.buffer.GetLine(row)) {
+        row++
+    }
+    if row > last {
+        row = last
+    }
+    tab.cursor.Row = row
+    tab.cursor.Col = 0
+    if row == last {
+        tab.cursor.Col = len(tab.buffer.GetLine(row))
+    }
+}